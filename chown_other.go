@@ -0,0 +1,11 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package ini
+
+import "os"
+
+// chown does nothing, files have no unix owner on this system
+func chown(file *os.File, info os.FileInfo) error {
+	return nil
+}
@@ -0,0 +1,395 @@
+/*
+Command go-ini reads and edits ini files from the shell
+
+Usage :
+
+	go-ini get file.ini section item            print the values of an item, one per line
+	go-ini set file.ini section item value      set an item, creating the file, section and item if needed
+	go-ini add file.ini section [item value]    add a section, an item or one more value to an item
+	go-ini delete file.ini section [item]       delete a section or an item
+	go-ini rename file.ini section [item] name  rename a section or an item
+	go-ini sections file.ini                    print the sections, one per line
+	go-ini items file.ini section               print the items of a section, one per line
+	go-ini comments file.ini section [item]     print the comments of a section or an item, one per line
+	go-ini lint [-json] file.ini...             report the problems of files, see ini.LintString
+	go-ini fmt [-w|-check] [flags] file.ini...  format files, see ini.Format and go-ini fmt -h
+
+The files are edited keeping their original lines, and written atomically.
+
+Exit codes :
+
+	0  success
+	1  section or item not found
+	2  invalid command line
+	3  file unreadable or invalid
+	4  file not written
+	5  problems found by lint, or files not formatted with fmt -check
+*/
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+
+	ini "github.com/ryosama/go-ini"
+)
+
+// Exit codes
+const (
+	exitOK = iota
+	exitNotFound
+	exitUsage
+	exitInvalid
+	exitWrite
+	exitFindings
+)
+
+// command is a sub command, run receives the arguments following its name
+type command struct {
+	usage string
+	run   func(args []string, stdout io.Writer, stderr io.Writer) int
+}
+
+var commands map[string]command
+
+func init() { // commands refers to itself through the usage of each command
+	commands = map[string]command{
+		"get":      {"get file.ini section item", runGet},
+		"set":      {"set file.ini section item value", runSet},
+		"add":      {"add file.ini section [item value]", runAdd},
+		"delete":   {"delete file.ini section [item]", runDelete},
+		"rename":   {"rename file.ini section [item] name", runRename},
+		"sections": {"sections file.ini", runSections},
+		"items":    {"items file.ini section", runItems},
+		"comments": {"comments file.ini section [item]", runComments},
+		"lint":     {"lint [-json] file.ini...", runLint},
+		"fmt":      {"fmt [-w|-check] [flags] file.ini...", runFmt},
+	}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit code
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		return usage(stderr)
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "go-ini: unknown command %q\n", args[0])
+		return usage(stderr)
+	}
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+// usage prints the available commands
+func usage(stderr io.Writer) int {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(stderr, "usage :")
+	for _, name := range names {
+		fmt.Fprintln(stderr, "  go-ini "+commands[name].usage)
+	}
+	return exitUsage
+}
+
+// checkArgs returns true if args holds between min and max arguments, printing the usage of name otherwise
+func checkArgs(name string, args []string, min int, max int, stderr io.Writer) bool {
+	if len(args) < min || len(args) > max {
+		fmt.Fprintln(stderr, "usage : go-ini "+commands[name].usage)
+		return false
+	}
+	return true
+}
+
+// load reads an ini file keeping its original lines, a missing file is empty if create is true.
+// The include directives are kept as lines of the file (ParseOptions.Includes is not set by default),
+// the included files are neither read nor written
+func load(filename string, create bool, stderr io.Writer) (*ini.Ini, int) {
+	myIni := new(ini.Ini)
+	myIni.Lossless = true
+	err := myIni.LoadFromFile(filename)
+	if err != nil && create && errors.Is(err, fs.ErrNotExist) {
+		empty := ""
+		err = myIni.LoadFromString(&empty)
+		myIni.Filename = filename
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, "go-ini:", err)
+		return nil, exitInvalid
+	}
+	return myIni, exitOK
+}
+
+// save writes myIni atomically to its file
+func save(myIni *ini.Ini, stderr io.Writer) int {
+	if err := myIni.Save(); err != nil {
+		fmt.Fprintln(stderr, "go-ini:", err)
+		return exitWrite
+	}
+	return exitOK
+}
+
+// notFound prints that a section or an item does not exists
+func notFound(stderr io.Writer, section string, item ...string) int {
+	if len(item) > 0 {
+		fmt.Fprintf(stderr, "go-ini: item %q not found in section %q\n", item[0], section)
+	} else {
+		fmt.Fprintf(stderr, "go-ini: section %q not found\n", section)
+	}
+	return exitNotFound
+}
+
+// printLines prints lines, one per line
+func printLines(stdout io.Writer, lines []string) int {
+	for _, line := range lines {
+		fmt.Fprintln(stdout, line)
+	}
+	return exitOK
+}
+
+func runGet(args []string, stdout io.Writer, stderr io.Writer) int {
+	if !checkArgs("get", args, 3, 3, stderr) {
+		return exitUsage
+	}
+	myIni, code := load(args[0], false, stderr)
+	if myIni == nil {
+		return code
+	}
+	values, ok := myIni.GetAll(args[1], args[2])
+	if !ok {
+		return notFound(stderr, args[1], args[2])
+	}
+	return printLines(stdout, values)
+}
+
+func runSet(args []string, stdout io.Writer, stderr io.Writer) int {
+	if !checkArgs("set", args, 4, 4, stderr) {
+		return exitUsage
+	}
+	myIni, code := load(args[0], true, stderr)
+	if myIni == nil {
+		return code
+	}
+	myIni.SetOrCreate(args[1], args[2], args[3])
+	return save(myIni, stderr)
+}
+
+func runAdd(args []string, stdout io.Writer, stderr io.Writer) int {
+	if !checkArgs("add", args, 2, 4, stderr) {
+		return exitUsage
+	}
+	if len(args) == 3 { // an item needs a value
+		fmt.Fprintln(stderr, "usage : go-ini "+commands["add"].usage)
+		return exitUsage
+	}
+	myIni, code := load(args[0], true, stderr)
+	if myIni == nil {
+		return code
+	}
+	if len(args) == 2 {
+		myIni.AddSection(args[1])
+	} else if !myIni.AddItem(args[1], args[2], args[3]) { // already exists
+		myIni.AddValue(args[1], args[2], args[3])
+	}
+	return save(myIni, stderr)
+}
+
+func runDelete(args []string, stdout io.Writer, stderr io.Writer) int {
+	if !checkArgs("delete", args, 2, 3, stderr) {
+		return exitUsage
+	}
+	myIni, code := load(args[0], false, stderr)
+	if myIni == nil {
+		return code
+	}
+	if len(args) == 2 {
+		if !myIni.DeleteSection(args[1]) {
+			return notFound(stderr, args[1])
+		}
+	} else if !myIni.DeleteItem(args[1], args[2]) {
+		return notFound(stderr, args[1], args[2])
+	}
+	return save(myIni, stderr)
+}
+
+func runRename(args []string, stdout io.Writer, stderr io.Writer) int {
+	if !checkArgs("rename", args, 3, 4, stderr) {
+		return exitUsage
+	}
+	myIni, code := load(args[0], false, stderr)
+	if myIni == nil {
+		return code
+	}
+	if len(args) == 3 {
+		if !myIni.RenameSection(args[1], args[2]) {
+			return notFound(stderr, args[1])
+		}
+	} else if !myIni.RenameItem(args[1], args[2], args[3]) {
+		return notFound(stderr, args[1], args[2])
+	}
+	return save(myIni, stderr)
+}
+
+func runSections(args []string, stdout io.Writer, stderr io.Writer) int {
+	if !checkArgs("sections", args, 1, 1, stderr) {
+		return exitUsage
+	}
+	myIni, code := load(args[0], false, stderr)
+	if myIni == nil {
+		return code
+	}
+	return printLines(stdout, myIni.GetSections())
+}
+
+func runItems(args []string, stdout io.Writer, stderr io.Writer) int {
+	if !checkArgs("items", args, 2, 2, stderr) {
+		return exitUsage
+	}
+	myIni, code := load(args[0], false, stderr)
+	if myIni == nil {
+		return code
+	}
+	if !myIni.SectionExists(args[1]) {
+		return notFound(stderr, args[1])
+	}
+	return printLines(stdout, myIni.GetItems(args[1]))
+}
+
+func runComments(args []string, stdout io.Writer, stderr io.Writer) int {
+	if !checkArgs("comments", args, 2, 3, stderr) {
+		return exitUsage
+	}
+	myIni, code := load(args[0], false, stderr)
+	if myIni == nil {
+		return code
+	}
+	if len(args) == 2 {
+		if !myIni.SectionExists(args[1]) {
+			return notFound(stderr, args[1])
+		}
+		return printLines(stdout, myIni.GetSectionComments(args[1]))
+	}
+	if !myIni.ItemExists(args[1], args[2]) {
+		return notFound(stderr, args[1], args[2])
+	}
+	return printLines(stdout, myIni.GetItemComments(args[1], args[2]))
+}
+
+func runLint(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	jsonOutput := flags.Bool("json", false, "print the findings as a JSON array")
+	if flags.Parse(args) != nil || !checkArgs("lint", flags.Args(), 1, math.MaxInt32, stderr) {
+		return exitUsage
+	}
+
+	code := exitOK
+	findings := make([]ini.Finding, 0)
+	for _, filename := range flags.Args() {
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintln(stderr, "go-ini:", err)
+			code = exitInvalid
+			continue
+		}
+		findings = append(findings, ini.LintString(string(content), filename)...)
+	}
+
+	if *jsonOutput {
+		output, _ := json.MarshalIndent(findings, "", "  ")
+		fmt.Fprintln(stdout, string(output))
+	} else {
+		for _, finding := range findings {
+			fmt.Fprintln(stdout, finding.String())
+		}
+	}
+	if code == exitOK && len(findings) > 0 {
+		code = exitFindings
+	}
+	return code
+}
+
+func runFmt(args []string, stdout io.Writer, stderr io.Writer) int {
+	style := ini.DefaultStyle()
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	write := flags.Bool("w", false, "rewrite the files instead of printing them")
+	check := flags.Bool("check", false, "print the files not formatted, without changing them")
+	flags.StringVar(&style.Indent, "indent", style.Indent, "indentation of the items")
+	compact := flags.Bool("compact", false, "write key=value rather than key = value")
+	flags.StringVar(&style.CommentMarker, "comment", style.CommentMarker, "comment marker, ; or #")
+	flags.IntVar(&style.BlankLines, "blank", style.BlankLines, "blank lines between sections")
+	crlf := flags.Bool("crlf", false, "end the lines with \\r\\n")
+	flags.BoolVar(&style.SortKeys, "sort", style.SortKeys, "sort the items of each section")
+	if flags.Parse(args) != nil || !checkArgs("fmt", flags.Args(), 1, math.MaxInt32, stderr) || (*write && *check) {
+		if *write && *check {
+			fmt.Fprintln(stderr, "go-ini: -w and -check are exclusive")
+		}
+		return exitUsage
+	}
+	style.SpaceAroundEqual = !*compact
+	if *crlf {
+		style.LineEnding = ini.LineEndingCRLF
+	}
+
+	code := exitOK
+	for _, filename := range flags.Args() {
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintln(stderr, "go-ini:", err)
+			code = exitInvalid
+			continue
+		}
+		original := string(content)
+		myIni := new(ini.Ini)
+		if err := myIni.LoadFromString(&original); err != nil { // keeps the include directives as they are
+			fmt.Fprintln(stderr, "go-ini:", filename+":", err)
+			code = exitInvalid
+			continue
+		}
+		myIni.Filename = filename
+		formatted := myIni.Format(style)
+
+		switch {
+		case *check:
+			if formatted != original {
+				fmt.Fprintln(stdout, filename)
+				if code == exitOK {
+					code = exitFindings
+				}
+			}
+		case *write:
+			if formatted == original {
+				continue
+			}
+			// reloaded losslessly, Save writes the formatted lines atomically
+			myIni.Lossless = true
+			if err := myIni.LoadFromString(&formatted); err != nil {
+				fmt.Fprintln(stderr, "go-ini:", filename+":", err)
+				code = exitInvalid
+				continue
+			}
+			if c := save(myIni, stderr); c != exitOK {
+				code = c
+			}
+		default:
+			fmt.Fprint(stdout, formatted)
+		}
+	}
+	return code
+}
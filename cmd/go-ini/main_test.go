@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.ini")
+	ioutil.WriteFile(filename, []byte("; main server\n[Server]\n  host  =  localhost\n[Log]\n; verbosity\nlevel=info\n"), 0644)
+
+	tests := []struct {
+		args   []string
+		code   int
+		stdout string
+	}{
+		{[]string{"get", filename, "Server", "host"}, exitOK, "localhost\n"},
+		{[]string{"get", filename, "Server", "port"}, exitNotFound, ""},
+		{[]string{"get", filepath.Join(dir, "missing.ini"), "Server", "host"}, exitInvalid, ""},
+		{[]string{"get", filename, "Server"}, exitUsage, ""},
+		{[]string{"unknown"}, exitUsage, ""},
+		{[]string{"set", filename, "Server", "port", "8080"}, exitOK, ""},
+		{[]string{"get", filename, "Server", "port"}, exitOK, "8080\n"},
+		{[]string{"add", filename, "Server", "alias", "a"}, exitOK, ""},
+		{[]string{"add", filename, "Server", "alias", "b"}, exitOK, ""},
+		{[]string{"get", filename, "Server", "alias"}, exitOK, "a\nb\n"},
+		{[]string{"add", filename, "Cache"}, exitOK, ""},
+		{[]string{"sections", filename}, exitOK, "Server\nLog\nCache\n"},
+		{[]string{"rename", filename, "Cache", "Store"}, exitOK, ""},
+		{[]string{"rename", filename, "Server", "alias", "aliases"}, exitOK, ""},
+		{[]string{"rename", filename, "Nothing", "Store"}, exitNotFound, ""},
+		{[]string{"items", filename, "Server"}, exitOK, "host\nport\naliases\n"},
+		{[]string{"items", filename, "Nothing"}, exitNotFound, ""},
+		{[]string{"comments", filename, "Server"}, exitOK, "main server\n"},
+		{[]string{"comments", filename, "Log", "level"}, exitOK, "verbosity\n"},
+		{[]string{"delete", filename, "Store"}, exitOK, ""},
+		{[]string{"delete", filename, "Server", "aliases"}, exitOK, ""},
+		{[]string{"delete", filename, "Server", "aliases"}, exitNotFound, ""},
+	}
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		code := run(test.args, &stdout, &stderr)
+		if code != test.code || stdout.String() != test.stdout {
+			t.Error("For", strings.Join(test.args, " "), "expected", test.code, test.stdout, "got", code, stdout.String(), stderr.String())
+		}
+	}
+
+	// the original lines are kept ///////////////////////////////////////////////////////
+	content, _ := ioutil.ReadFile(filename)
+	if !strings.Contains(string(content), "  host  =  localhost\n") {
+		t.Error("For", "set", "expected", "original line kept", "got", string(content))
+	}
+
+	// invalid file ///////////////////////////////////////////////////////
+	invalid := filepath.Join(dir, "invalid.ini")
+	ioutil.WriteFile(invalid, []byte("[Server]\nnot an item\n"), 0644)
+	if code := run([]string{"set", invalid, "Server", "host", "x"}, &bytes.Buffer{}, &bytes.Buffer{}); code != exitInvalid {
+		t.Error("For", "set invalid.ini", "expected", exitInvalid, "got", code)
+	}
+
+	// new file ///////////////////////////////////////////////////////
+	created := filepath.Join(dir, "new.ini")
+	if code := run([]string{"set", created, "Server", "host", "x"}, &bytes.Buffer{}, &bytes.Buffer{}); code != exitOK {
+		t.Error("For", "set new.ini", "expected", exitOK, "got", code)
+	}
+	var stdout bytes.Buffer
+	if run([]string{"get", created, "Server", "host"}, &stdout, &bytes.Buffer{}); stdout.String() != "x\n" {
+		t.Error("For", "get new.ini", "expected", "x", "got", stdout.String())
+	}
+
+	// the include directives are kept, the included files are not inlined ///////////////////////////////////////////////////////
+	os.Mkdir(filepath.Join(dir, "conf.d"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "conf.d", "extra.ini"), []byte("[extra]\nb = 2\n"), 0644)
+	app := filepath.Join(dir, "app.ini")
+	ioutil.WriteFile(app, []byte("[main]\na = 1\n!includedir conf.d\n"), 0644)
+	if code := run([]string{"set", app, "main", "a", "5"}, &bytes.Buffer{}, &bytes.Buffer{}); code != exitOK {
+		t.Error("For", "set app.ini", "expected", exitOK, "got", code)
+	}
+	content, _ = ioutil.ReadFile(app)
+	if s := string(content); s != "[main]\n  a = 5\n!includedir conf.d\n" {
+		t.Error("For", "set app.ini", "expected", "[main]\n  a = 5\n!includedir conf.d\n", "got", s)
+	}
+
+	// lint ///////////////////////////////////////////////////////
+	ioutil.WriteFile(invalid, []byte("[Server]\nhost=a \nnot an item\n"), 0644)
+	stdout.Reset()
+	if code := run([]string{"lint", "-json", invalid}, &stdout, &bytes.Buffer{}); code != exitFindings {
+		t.Error("For", "lint", "expected", exitFindings, "got", code)
+	}
+	var findings []map[string]interface{}
+	json.Unmarshal(stdout.Bytes(), &findings)
+	if len(findings) != 2 || findings[0]["rule"] != "trailing-whitespace" || findings[1]["severity"] != "error" || findings[1]["line"] != 3.0 {
+		t.Error("For", "lint -json", "expected", "2 findings", "got", stdout.String())
+	}
+	if code := run([]string{"lint", created}, &bytes.Buffer{}, &bytes.Buffer{}); code != exitOK {
+		t.Error("For", "lint new.ini", "expected", exitOK, "got", code)
+	}
+
+	// fmt ///////////////////////////////////////////////////////
+	unformatted := filepath.Join(dir, "unformatted.ini")
+	ioutil.WriteFile(unformatted, []byte("# main\r\n[b]\r\n  y=2  \r\n  x = 1\r\n[a]\r\nz=3\r\n"), 0644)
+	stdout.Reset()
+	if code := run([]string{"fmt", "-check", unformatted}, &stdout, &bytes.Buffer{}); code != exitFindings || stdout.String() != unformatted+"\n" {
+		t.Error("For", "fmt -check", "expected", exitFindings, "got", code, stdout.String())
+	}
+	if code := run([]string{"fmt", "-w", "-sort", unformatted}, &bytes.Buffer{}, &bytes.Buffer{}); code != exitOK {
+		t.Error("For", "fmt -w", "expected", exitOK, "got", code)
+	}
+	content, _ = ioutil.ReadFile(unformatted)
+	expected := "; main\n[b]\nx = 1\ny = 2\n\n[a]\nz = 3\n"
+	if string(content) != expected {
+		t.Error("For", "fmt -w", "expected", expected, "got", string(content))
+	}
+	if code := run([]string{"fmt", "-check", "-sort", unformatted}, &bytes.Buffer{}, &bytes.Buffer{}); code != exitOK {
+		t.Error("For", "fmt -check", "expected", exitOK, "got", code)
+	}
+	ioutil.WriteFile(unformatted, []byte("[mysqld]\nport=3306\n!includedir /etc/mysql/conf.d/\n"), 0644)
+	if code := run([]string{"fmt", "-w", unformatted}, &bytes.Buffer{}, &bytes.Buffer{}); code != exitOK {
+		t.Error("For", "fmt -w !includedir", "expected", exitOK, "got", code)
+	}
+	content, _ = ioutil.ReadFile(unformatted)
+	if expected = "[mysqld]\nport = 3306\n!includedir /etc/mysql/conf.d/\n"; string(content) != expected {
+		t.Error("For", "fmt -w !includedir", "expected", expected, "got", string(content))
+	}
+}
@@ -0,0 +1,779 @@
+package ini
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	reBareKey    = regexp.MustCompile("^[A-Za-z0-9_-]+$")
+	rePlainValue = regexp.MustCompile("^[A-Za-z0-9_./(][A-Za-z0-9_./@()+=,:;~ -]*$")
+)
+
+// reset empties ini before loading another format, with the default formatting
+func (ini *Ini) reset() {
+	ini.parse("", "", DefaultParseOptions(), osFiles)
+}
+
+// appendValue adds an item, or one more value if it exists, creating the section if needed
+func (ini *Ini) appendValue(section string, item string, value string) {
+	if !ini.AddItem(section, item, value) {
+		ini.AddValue(section, item, value)
+	}
+}
+
+// topFirst moves the items outside any section before the first section, where they are written
+func (ini *Ini) topFirst() {
+	if ini.SectionExists("") {
+		ini.order = append([]string{""}, removeName(ini.order, "")...)
+	}
+}
+
+// lineError returns the error of a line read from another format
+func lineError(line int, text string, reason string) *ParseError {
+	return &ParseError{Line: line, Column: 1, Text: text, Reason: reason}
+}
+
+// readLines calls fn for each line of r, with its number starting at 1, until fn returns an error
+func readLines(r io.Reader, fn func(number int, line string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for number := 1; scanner.Scan(); number++ {
+		if err := fn(number, strings.TrimRight(scanner.Text(), "\r")); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+/*
+SprintJSON returns the content as a JSON object of objects, in document order : one object per section holding
+its items, and the items outside any section at the top level. An item with several values is an array
+
+With comments, the comments of a section are in a "#comments" array, each item is an object holding
+"value" (or "values" for several values) and "comments", and the items outside any section are in the
+"" object. An object at the top level is always a section
+
+Example :
+
+	{
+	  "Server": {
+	    "host": "localhost",
+	    "alias": ["www", "api"]
+	  }
+	}
+*/
+func (ini *Ini) SprintJSON(withComments bool) string {
+	array := func(values []string) string {
+		quoted := make([]string, len(values))
+		for i, v := range values {
+			quoted[i] = quoteString(v)
+		}
+		return "[" + strings.Join(quoted, ", ") + "]"
+	}
+	item := func(section string, name string) string {
+		values := ini.rawValues(section, name)
+		value, key := array(values), "\"values\""
+		if len(values) == 1 {
+			value, key = quoteString(values[0]), "\"value\""
+		}
+		if withComments {
+			return "{" + key + ": " + value + ", \"comments\": " + array(ini.GetItemComments(section, name)) + "}"
+		}
+		return value
+	}
+	object := func(indent string, members []string) string {
+		if len(members) == 0 {
+			return "{}"
+		}
+		return "{\n" + indent + "  " + strings.Join(members, ",\n"+indent+"  ") + "\n" + indent + "}"
+	}
+
+	members := make([]string, 0)
+	if !withComments { // an item with comments is an object, read as a section at the top level
+		for _, name := range ini.GetItems("") {
+			members = append(members, quoteString(name)+": "+item("", name))
+		}
+	}
+	for _, section := range ini.GetSections() {
+		if section == "" && !withComments {
+			continue
+		}
+		items := make([]string, 0)
+		if withComments {
+			items = append(items, "\"#comments\": "+array(ini.GetSectionComments(section)))
+		}
+		for _, name := range ini.GetItems(section) {
+			items = append(items, quoteString(name)+": "+item(section, name))
+		}
+		members = append(members, quoteString(section)+": "+object("  ", items))
+	}
+	return object("", members) + "\n"
+}
+
+/*
+LoadJSON replaces the content with a JSON object of objects, as written by SprintJSON. Numbers and
+booleans are stored as their text, null as an empty value and arrays as several values
+
+Example :
+
+	err := myIni.LoadJSON(strings.NewReader(`{"Server": {"host": "localhost", "port": 80}}`))
+*/
+func (ini *Ini) LoadJSON(r io.Reader) error {
+	ini.reset()
+
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		name := token.(string)
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return err
+		}
+		if isJSONObject(raw) { // a section, the items are one level below
+			ini.AddSection(name)
+			if err := ini.loadJSONSection(name, raw); err != nil {
+				return err
+			}
+		} else if err := ini.loadJSONItem("", name, raw); err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(decoder, '}'); err != nil {
+		return err
+	}
+	ini.topFirst()
+	return nil
+}
+
+// loadJSONSection adds the items of the JSON object raw to a section
+func (ini *Ini) loadJSONSection(section string, raw json.RawMessage) error {
+	decoder := json.NewDecoder(strings.NewReader(string(raw)))
+	decoder.UseNumber()
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+
+		name := token.(string)
+		if name == "#comments" {
+			var comments []string
+			if err := json.Unmarshal(value, &comments); err != nil {
+				return fmt.Errorf("json: section %q: invalid comments: %v", section, err)
+			}
+			for _, comment := range comments {
+				ini.AddSectionComment(section, comment)
+			}
+			continue
+		}
+		if err := ini.loadJSONItem(section, name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadJSONItem adds an item from the JSON value raw : a scalar, an array of scalars or an object with comments
+func (ini *Ini) loadJSONItem(section string, item string, raw json.RawMessage) error {
+	var value interface{}
+	decoder := json.NewDecoder(strings.NewReader(string(raw)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return err
+	}
+
+	var comments []interface{}
+	if object, ok := value.(map[string]interface{}); ok { // item with comments
+		for key := range object {
+			if key != "value" && key != "values" && key != "comments" {
+				return fmt.Errorf("json: item %q of section %q: unexpected member %q", item, section, key)
+			}
+		}
+		if v, ok := object["values"]; ok {
+			value = v
+		} else {
+			value = object["value"]
+		}
+		comments, _ = object["comments"].([]interface{})
+	}
+
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	} else if len(values) == 0 { // an item has at least one value
+		values = []interface{}{""}
+	}
+	for _, v := range values {
+		text, ok := jsonText(v)
+		if !ok {
+			return fmt.Errorf("json: item %q of section %q: invalid value %s", item, section, string(raw))
+		}
+		ini.appendValue(section, item, text)
+	}
+	for _, comment := range comments {
+		if text, ok := jsonText(comment); ok {
+			ini.AddItemComment(section, item, text)
+		}
+	}
+	return nil
+}
+
+// isJSONObject returns true if raw is a JSON object
+func isJSONObject(raw json.RawMessage) bool {
+	return strings.HasPrefix(strings.TrimSpace(string(raw)), "{")
+}
+
+// jsonText returns the text of a JSON scalar, false for an array or an object
+func jsonText(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return "", true
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// expectDelim reads the next token of decoder, which must be delim
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("json: expected %q, got %v", delim, token)
+	}
+	return nil
+}
+
+// unescapeDollars replaces the \$ escapes of a dotenv double quoted value by the \u0024 escape of unquoteString
+func unescapeDollars(raw string) string {
+	var b strings.Builder
+	for i := 0; i < len(raw); i++ {
+		switch {
+		case raw[i] != '\\' || i+1 == len(raw):
+			b.WriteByte(raw[i])
+		case raw[i+1] == '$':
+			b.WriteString("\\u0024")
+			i++
+		default: // another escape, kept with its character
+			b.WriteString(raw[i : i+2])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// yamlScalar returns s as a YAML scalar, quoted if needed
+func yamlScalar(s string) string {
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return quoteString(s)
+	}
+	if !rePlainValue.MatchString(s) || strings.HasSuffix(s, " ") || strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return quoteString(s)
+	}
+	return s
+}
+
+/*
+SprintYAML returns the content as a YAML mapping of mappings, in document order : one mapping per section
+holding its items, and the items outside any section at the top level. An item with several values is a sequence
+
+Example :
+
+	Server:
+	  host: localhost
+	  alias:
+	    - www
+	    - api
+*/
+func (ini *Ini) SprintYAML() string {
+	var b strings.Builder
+	items := func(indent string, section string) {
+		for _, item := range ini.GetItems(section) {
+			values := ini.rawValues(section, item)
+			if len(values) <= 1 {
+				b.WriteString(indent + yamlScalar(item) + ": " + yamlScalar(strings.Join(values, "")) + "\n")
+				continue
+			}
+			b.WriteString(indent + yamlScalar(item) + ":\n")
+			for _, v := range values {
+				b.WriteString(indent + "  - " + yamlScalar(v) + "\n")
+			}
+		}
+	}
+
+	items("", "")
+	for _, section := range ini.GetSections() {
+		if section == "" {
+			continue
+		}
+		if len(ini.GetItems(section)) == 0 {
+			b.WriteString(yamlScalar(section) + ": {}\n")
+			continue
+		}
+		b.WriteString(yamlScalar(section) + ":\n")
+		items("  ", section)
+	}
+	return b.String()
+}
+
+// parseYAMLScalar returns the value of a YAML scalar, quoted or plain with an optional comment
+func parseYAMLScalar(s string) (string, error) {
+	var value, rest string
+	var err error
+	switch {
+	case strings.HasPrefix(s, "\""):
+		value, rest, err = unquoteString(s)
+	case strings.HasPrefix(s, "'"):
+		value, rest, err = unquoteLiteral(s, true)
+	default:
+		if i := strings.Index(s, " #"); i >= 0 {
+			s = s[:i]
+		}
+		return strings.TrimSpace(s), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", fmt.Errorf("unexpected %q after the quoted value", rest)
+	}
+	return value, nil
+}
+
+// splitYAMLKey splits "key: value" into the unquoted key and the raw value
+func splitYAMLKey(s string) (string, string, error) {
+	if strings.HasPrefix(s, "\"") || strings.HasPrefix(s, "'") {
+		var key, rest string
+		var err error
+		if s[0] == '"' {
+			key, rest, err = unquoteString(s)
+		} else {
+			key, rest, err = unquoteLiteral(s, true)
+		}
+		if err != nil {
+			return "", "", err
+		}
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", fmt.Errorf("missing colon after the key")
+		}
+		return key, strings.TrimSpace(rest[1:]), nil
+	}
+
+	if i := strings.Index(s, ": "); i >= 0 {
+		return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+2:]), nil
+	}
+	if strings.HasSuffix(s, ":") {
+		return strings.TrimSpace(s[:len(s)-1]), "", nil
+	}
+	return "", "", fmt.Errorf("missing colon after the key")
+}
+
+/*
+LoadYAML replaces the content with a YAML mapping of mappings, as written by SprintYAML
+
+Only this subset of YAML is read : block mappings two levels deep, block sequences of scalars for several
+values, plain, single and double quoted scalars and comments
+
+Example :
+
+	err := myIni.LoadYAML(file)
+*/
+func (ini *Ini) LoadYAML(r io.Reader) error {
+	ini.reset()
+
+	section, pending := "", "" // pending is a key of the first level without value yet
+	var list *[2]string        // item receiving the values of a sequence
+
+	flush := func() { // a key of the first level followed by nothing is an empty section
+		if pending != "" {
+			ini.AddSection(pending)
+			section, pending = pending, ""
+		}
+	}
+
+	err := readLines(r, func(number int, line string) error {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			return nil
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if strings.HasPrefix(line[indent:], "\t") {
+			return lineError(number, line, "tabulation in indentation")
+		}
+
+		if trimmed == "-" || strings.HasPrefix(trimmed, "- ") { // value of a sequence
+			if list == nil && pending != "" && indent > 0 { // sequence of an item outside any section
+				list = &[2]string{"", pending}
+				pending = ""
+			}
+			if list == nil {
+				return lineError(number, line, "sequence outside an item")
+			}
+			value, err := parseYAMLScalar(strings.TrimSpace(trimmed[1:]))
+			if err != nil {
+				return lineError(number, line, err.Error())
+			}
+			ini.appendValue(list[0], list[1], value)
+			return nil
+		}
+
+		key, raw, err := splitYAMLKey(trimmed)
+		if err != nil {
+			return lineError(number, line, err.Error())
+		}
+		list = nil
+
+		if indent == 0 {
+			flush()
+			section = ""
+			switch raw {
+			case "":
+				pending = key
+			case "{}":
+				ini.AddSection(key)
+			default:
+				value, err := parseYAMLScalar(raw)
+				if err != nil {
+					return lineError(number, line, err.Error())
+				}
+				ini.appendValue("", key, value)
+			}
+			return nil
+		}
+
+		flush()
+		if section == "" {
+			return lineError(number, line, "indented item outside any section")
+		}
+		switch raw {
+		case "":
+			list = &[2]string{section, key}
+		default:
+			value, err := parseYAMLScalar(raw)
+			if err != nil {
+				return lineError(number, line, err.Error())
+			}
+			ini.appendValue(section, key, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	flush()
+	ini.topFirst()
+	return nil
+}
+
+// tomlKey returns a TOML key, quoted if needed
+func tomlKey(s string) string {
+	if reBareKey.MatchString(s) {
+		return s
+	}
+	return quoteString(s)
+}
+
+/*
+SprintTOML returns the content as TOML, in document order : one table per section holding its items as
+strings, and the items outside any section before the first table. An item with several values is an array
+
+Example :
+
+	[Server]
+	host = "localhost"
+	alias = ["www", "api"]
+*/
+func (ini *Ini) SprintTOML() string {
+	var b strings.Builder
+	items := func(section string) {
+		for _, item := range ini.GetItems(section) {
+			values := ini.rawValues(section, item)
+			value := ""
+			if len(values) == 1 {
+				value = quoteString(values[0])
+			} else {
+				quoted := make([]string, len(values))
+				for i, v := range values {
+					quoted[i] = quoteString(v)
+				}
+				value = "[" + strings.Join(quoted, ", ") + "]"
+			}
+			b.WriteString(tomlKey(item) + " = " + value + "\n")
+		}
+	}
+
+	items("")
+	for _, section := range ini.GetSections() {
+		if section == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("[" + tomlKey(section) + "]\n")
+		items(section)
+	}
+	return b.String()
+}
+
+// parseTOMLKey reads the bare or quoted key at the start of s, returns it with the rest of s
+func parseTOMLKey(s string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(s, "\""):
+		return unquoteString(s)
+	case strings.HasPrefix(s, "'"):
+		return unquoteLiteral(s, false)
+	}
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '-' || r == '.')
+	})
+	if end < 0 {
+		end = len(s)
+	}
+	if end == 0 {
+		return "", s, fmt.Errorf("missing key")
+	}
+	return s[:end], s[end:], nil
+}
+
+// parseTOMLValue reads the value at the start of s : a string, a literal (number, boolean, date) or an array of them
+func parseTOMLValue(s string) ([]string, string, error) {
+	if !strings.HasPrefix(s, "[") {
+		value, rest, err := parseTOMLScalar(s)
+		return []string{value}, rest, err
+	}
+
+	values := make([]string, 0, 1)
+	rest := strings.TrimSpace(s[1:])
+	for !strings.HasPrefix(rest, "]") {
+		value, r, err := parseTOMLScalar(rest)
+		if err != nil {
+			return nil, s, err
+		}
+		values = append(values, value)
+		rest = strings.TrimSpace(r)
+		if strings.HasPrefix(rest, ",") {
+			rest = strings.TrimSpace(rest[1:])
+		} else if !strings.HasPrefix(rest, "]") {
+			return nil, s, fmt.Errorf("missing closing bracket")
+		}
+	}
+	if len(values) == 0 { // an item has at least one value
+		values = append(values, "")
+	}
+	return values, rest[1:], nil
+}
+
+// parseTOMLScalar reads the string or literal at the start of s
+func parseTOMLScalar(s string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(s, "\"\"\"") || strings.HasPrefix(s, "'''"):
+		return "", s, fmt.Errorf("multi-line strings are not supported")
+	case strings.HasPrefix(s, "\""):
+		return unquoteString(s)
+	case strings.HasPrefix(s, "'"):
+		return unquoteLiteral(s, false)
+	case strings.HasPrefix(s, "[") || strings.HasPrefix(s, "{"):
+		return "", s, fmt.Errorf("nested arrays and inline tables are not supported")
+	}
+	end := strings.IndexAny(s, ",]#")
+	if end < 0 {
+		end = len(s)
+	}
+	value := strings.TrimSpace(s[:end])
+	if value == "" {
+		return "", s, fmt.Errorf("missing value")
+	}
+	return value, s[end:], nil
+}
+
+/*
+LoadTOML replaces the content with TOML, as written by SprintTOML : each table is a section, the keys before
+the first table are outside any section
+
+Only this subset of TOML is read : tables, single line strings, literals (numbers, booleans, dates) and arrays
+of them, each value on one line. Numbers, booleans and dates are stored as their text
+
+Example :
+
+	err := myIni.LoadTOML(file)
+*/
+func (ini *Ini) LoadTOML(r io.Reader) error {
+	ini.reset()
+
+	section := ""
+	err := readLines(r, func(number int, line string) error {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			return nil
+		}
+
+		var rest string
+		if strings.HasPrefix(trimmed, "[[") {
+			return lineError(number, line, "arrays of tables are not supported")
+		} else if strings.HasPrefix(trimmed, "[") {
+			name, r, err := parseTOMLKey(strings.TrimSpace(trimmed[1:]))
+			if err != nil {
+				return lineError(number, line, err.Error())
+			}
+			r = strings.TrimSpace(r)
+			if !strings.HasPrefix(r, "]") {
+				return lineError(number, line, "missing closing bracket")
+			}
+			section, rest = name, r[1:]
+			ini.AddSection(section)
+		} else {
+			key, r, err := parseTOMLKey(trimmed)
+			if err != nil {
+				return lineError(number, line, err.Error())
+			}
+			r = strings.TrimSpace(r)
+			if !strings.HasPrefix(r, "=") {
+				return lineError(number, line, "missing equal sign")
+			}
+			values, r, err := parseTOMLValue(strings.TrimSpace(r[1:]))
+			if err != nil {
+				return lineError(number, line, err.Error())
+			}
+			for _, value := range values {
+				ini.appendValue(section, key, value)
+			}
+			rest = r
+		}
+
+		if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+			return lineError(number, line, "unexpected "+strconv.Quote(rest))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	ini.topFirst()
+	return nil
+}
+
+/*
+SprintDotenv returns the content as a dotenv file : one SECTION_ITEM=value line per item, named by EnvName.
+A value is double quoted when needed, with "$" escaped, an item with several values keeps its last value like Get
+
+The export is lossy and meant for the environment of a program, not to be read back : the names are upper
+cased, the comments are dropped and a section or an item holding "_" cannot be told apart by LoadDotenv
+([my_app] host=h is written MY_APP_HOST=h, read back as the item APP_HOST of the section MY)
+
+Example :
+
+	SERVER_HOST=localhost
+	SERVER_BANNER="Welcome home"
+*/
+func (ini *Ini) SprintDotenv() string {
+	var b strings.Builder
+	for _, section := range ini.GetSections() {
+		for _, item := range ini.GetItems(section) {
+			values := ini.rawValues(section, item)
+			value := ""
+			if len(values) > 0 {
+				value = values[len(values)-1]
+			}
+			if strings.ContainsAny(value, " \t\r\n\"'\\#$`=") || value != strings.TrimSpace(value) {
+				value = strings.ReplaceAll(quoteString(value), "$", "\\$") // not expanded by the shell
+			}
+			b.WriteString(EnvName(section, item) + "=" + value + "\n")
+		}
+	}
+	return b.String()
+}
+
+/*
+LoadDotenv replaces the content with a dotenv file : each SECTION_ITEM=value line is the item ITEM of the
+section SECTION, split at the first "_", a name without "_" is outside any section. The names are not
+converted back, see SprintDotenv
+
+Lines may start with "export", values may be double quoted with escapes (including \$), single quoted or plain
+with a comment
+
+Example :
+
+	err := myIni.LoadDotenv(file)
+*/
+func (ini *Ini) LoadDotenv(r io.Reader) error {
+	ini.reset()
+
+	err := readLines(r, func(number int, line string) error {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			return nil
+		}
+		trimmed = strings.TrimSpace(strings.TrimPrefix(trimmed, "export "))
+
+		equal := strings.Index(trimmed, "=")
+		if equal <= 0 {
+			return lineError(number, line, "missing equal sign")
+		}
+		name, raw := strings.TrimSpace(trimmed[:equal]), strings.TrimSpace(trimmed[equal+1:])
+
+		var value, rest string
+		var err error
+		switch {
+		case strings.HasPrefix(raw, "\""):
+			value, rest, err = unquoteString(unescapeDollars(raw))
+		case strings.HasPrefix(raw, "'"):
+			value, rest, err = unquoteLiteral(raw, false)
+		default:
+			if i := strings.Index(raw, " #"); i >= 0 {
+				raw = raw[:i]
+			}
+			value = strings.TrimSpace(raw)
+		}
+		if err != nil {
+			return lineError(number, line, err.Error())
+		}
+		if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+			return lineError(number, line, "unexpected "+strconv.Quote(rest))
+		}
+
+		section, item := "", name
+		if i := strings.Index(name, "_"); i > 0 && i < len(name)-1 {
+			section, item = name[:i], name[i+1:]
+		}
+		if ini.ItemExists(section, item) { // the last line wins
+			ini.SetItem(section, item, value)
+		} else {
+			ini.AddItem(section, item, value)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	ini.topFirst()
+	return nil
+}
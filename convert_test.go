@@ -0,0 +1,142 @@
+package ini
+
+import (
+	"strings"
+	"testing"
+)
+
+const convertContent = "top=1\n; main server\n[Server]\n; listen address\nhost=localhost\nbanner=Welcome \"home\": yes\nalias[]=www\nalias[]=api\n[Empty]\n"
+
+// checkConverted checks the content of an Ini loaded from another format
+func checkConverted(t *testing.T, format string, myIni *Ini) {
+	tests := []struct {
+		section, item string
+		values        []string
+	}{
+		{"", "top", []string{"1"}},
+		{"Server", "host", []string{"localhost"}},
+		{"Server", "banner", []string{"Welcome \"home\": yes"}},
+		{"Server", "alias", []string{"www", "api"}},
+	}
+	for _, test := range tests {
+		if values, _ := myIni.GetAll(test.section, test.item); !sameValues(values, test.values) {
+			t.Error("For", format, test.section, test.item, "expected", test.values, "got", values)
+		}
+	}
+	if sections := myIni.GetSections(); !sameValues(sections, []string{"", "Server", "Empty"}) {
+		t.Error("For", format, "GetSections", "expected", []string{"", "Server", "Empty"}, "got", sections)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	myIni := new(Ini)
+	content := convertContent
+	if err := myIni.LoadFromString(&content); err != nil {
+		t.Fatal(err)
+	}
+
+	// JSON ///////////////////////////////////////////////////////
+	expected := "{\n  \"top\": \"1\",\n  \"Server\": {\n    \"host\": \"localhost\",\n    \"banner\": \"Welcome \\\"home\\\": yes\",\n" +
+		"    \"alias\": [\"www\", \"api\"]\n  },\n  \"Empty\": {}\n}\n"
+	if s := myIni.SprintJSON(false); s != expected {
+		t.Error("For", "SprintJSON", "expected", expected, "got", s)
+	}
+	converted := new(Ini)
+	if err := converted.LoadJSON(strings.NewReader(myIni.SprintJSON(true))); err != nil {
+		t.Fatal("For", "LoadJSON", "got", err)
+	}
+	checkConverted(t, "JSON", converted)
+	if c := converted.GetSectionComments("Server"); !sameValues(c, []string{"main server"}) {
+		t.Error("For", "JSON GetSectionComments(Server)", "expected", "main server", "got", c)
+	}
+	if c := converted.GetItemComments("Server", "host"); !sameValues(c, []string{"listen address"}) {
+		t.Error("For", "JSON GetItemComments(Server,host)", "expected", "listen address", "got", c)
+	}
+	converted.LoadJSON(strings.NewReader(`{"Server": {"port": 80, "debug": false, "none": null}}`))
+	if s, _ := converted.Get("Server", "port"); s != "80" {
+		t.Error("For", "JSON number", "expected", "80", "got", s)
+	}
+	if s, _ := converted.Get("Server", "debug"); s != "false" {
+		t.Error("For", "JSON boolean", "expected", "false", "got", s)
+	}
+	if err := converted.LoadJSON(strings.NewReader(`{"Server": {"port": {"x": {}}}}`)); err == nil {
+		t.Error("For", "LoadJSON nested object", "expected", "error", "got", nil)
+	}
+	for _, withComments := range []bool{false, true} { // an item named like the members of an item with comments
+		option := new(Ini)
+		optionContent := "[Option]\nvalue=42\nvalues=1\ncomments=no\n"
+		option.LoadFromString(&optionContent)
+		if err := converted.LoadJSON(strings.NewReader(option.SprintJSON(withComments))); err != nil {
+			t.Fatal("For", "LoadJSON([Option])", "got", err)
+		}
+		if s := converted.SprintJSON(withComments); s != option.SprintJSON(withComments) {
+			t.Error("For", "LoadJSON([Option])", "expected", option.SprintJSON(withComments), "got", s)
+		}
+	}
+
+	// YAML ///////////////////////////////////////////////////////
+	expected = "top: 1\nServer:\n  host: localhost\n  banner: \"Welcome \\\"home\\\": yes\"\n  alias:\n    - www\n    - api\nEmpty: {}\n"
+	if s := myIni.SprintYAML(); s != expected {
+		t.Error("For", "SprintYAML", "expected", expected, "got", s)
+	}
+	converted = new(Ini)
+	if err := converted.LoadYAML(strings.NewReader("# comment\n" + expected)); err != nil {
+		t.Fatal("For", "LoadYAML", "got", err)
+	}
+	checkConverted(t, "YAML", converted)
+	err := converted.LoadYAML(strings.NewReader("Server:\n  host: localhost\n  port\n"))
+	if e, ok := err.(*ParseError); !ok || e.Line != 3 {
+		t.Error("For", "LoadYAML invalid", "expected", "error on line 3", "got", err)
+	}
+
+	// TOML ///////////////////////////////////////////////////////
+	expected = "top = \"1\"\n\n[Server]\nhost = \"localhost\"\nbanner = \"Welcome \\\"home\\\": yes\"\nalias = [\"www\", \"api\"]\n\n[Empty]\n"
+	if s := myIni.SprintTOML(); s != expected {
+		t.Error("For", "SprintTOML", "expected", expected, "got", s)
+	}
+	converted = new(Ini)
+	if err := converted.LoadTOML(strings.NewReader(expected)); err != nil {
+		t.Fatal("For", "LoadTOML", "got", err)
+	}
+	checkConverted(t, "TOML", converted)
+	converted.LoadTOML(strings.NewReader("[Server] # comment\nport = 80 # comment\n'path' = 'C:\\temp'\n"))
+	if s, _ := converted.Get("Server", "port"); s != "80" {
+		t.Error("For", "TOML number", "expected", "80", "got", s)
+	}
+	if s, _ := converted.Get("Server", "path"); s != "C:\\temp" {
+		t.Error("For", "TOML literal string", "expected", "C:\\temp", "got", s)
+	}
+
+	// dotenv ///////////////////////////////////////////////////////
+	expected = "TOP=1\nSERVER_HOST=localhost\nSERVER_BANNER=\"Welcome \\\"home\\\": yes\"\nSERVER_ALIAS=api\n"
+	if s := myIni.SprintDotenv(); s != expected {
+		t.Error("For", "SprintDotenv", "expected", expected, "got", s)
+	}
+	converted = new(Ini)
+	if err := converted.LoadDotenv(strings.NewReader("# comment\n" + expected + "export SERVER_PORT=80 # comment\n")); err != nil {
+		t.Fatal("For", "LoadDotenv", "got", err)
+	}
+	tests := map[[2]string]string{
+		{"", "TOP"}:          "1",
+		{"SERVER", "HOST"}:   "localhost",
+		{"SERVER", "BANNER"}: "Welcome \"home\": yes",
+		{"SERVER", "PORT"}:   "80",
+	}
+	for key, value := range tests {
+		if s, _ := converted.Get(key[0], key[1]); s != value {
+			t.Error("For", "dotenv", key, "expected", value, "got", s)
+		}
+	}
+
+	// $ is escaped in quoted values ///////////////////////////////////////////////////////
+	content = "[Server]\npassword=a $b \\$c\n"
+	myIni.LoadFromString(&content)
+	expected = "SERVER_PASSWORD=\"a \\$b \\\\\\$c\"\n"
+	if s := myIni.SprintDotenv(); s != expected {
+		t.Error("For", "SprintDotenv($)", "expected", expected, "got", s)
+	}
+	converted.LoadDotenv(strings.NewReader(expected))
+	if s, _ := converted.Get("SERVER", "PASSWORD"); s != "a $b \\$c" {
+		t.Error("For", "LoadDotenv($)", "expected", "a $b \\$c", "got", s)
+	}
+}
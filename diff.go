@@ -0,0 +1,297 @@
+package ini
+
+import "strings"
+
+// ChangeKind tells what a Change is about
+type ChangeKind int
+
+const (
+	// SectionAdded is a section of the new document only, followed by an ItemAdded change per item
+	SectionAdded ChangeKind = iota
+
+	// SectionRemoved is a section of the old document only, followed by an ItemRemoved change per item
+	SectionRemoved
+
+	// ItemAdded is an item of the new document only
+	ItemAdded
+
+	// ItemRemoved is an item of the old document only
+	ItemRemoved
+
+	// ItemChanged is an item with different values in both documents
+	ItemChanged
+
+	// SectionRenamed is a section of the old document found with the same items under NewSection, reported by Diff only
+	SectionRenamed
+
+	// SectionCommentsChanged is a section with different comments in both documents, reported by Diff only
+	SectionCommentsChanged
+
+	// ItemCommentsChanged is an item with different comments in both documents, reported by Diff only
+	ItemCommentsChanged
+)
+
+// String returns the name of the kind, like "item changed"
+func (k ChangeKind) String() string {
+	switch k {
+	case SectionAdded:
+		return "section added"
+	case SectionRemoved:
+		return "section removed"
+	case ItemAdded:
+		return "item added"
+	case ItemRemoved:
+		return "item removed"
+	case ItemChanged:
+		return "item changed"
+	case SectionRenamed:
+		return "section renamed"
+	case SectionCommentsChanged:
+		return "section comments changed"
+	case ItemCommentsChanged:
+		return "item comments changed"
+	}
+	return "unknown change"
+}
+
+// Change is a difference between two documents
+type Change struct {
+	Kind ChangeKind
+
+	// Section of the change
+	Section string
+
+	// Item of the change, empty for a section change
+	Item string
+
+	// New name of a renamed section
+	NewSection string
+
+	// Values of the item in the old and the new document, empty when the item does not exists
+	OldValues []string
+	NewValues []string
+
+	// Comments of the section or the item in the old and the new document, for a comments change
+	OldComments []string
+	NewComments []string
+}
+
+// rawValues returns the values of an item as loaded or set, without environment variables
+func (ini *Ini) rawValues(section string, item string) []string {
+	values := ini.data[section].items[item].values
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = v.text
+	}
+	return result
+}
+
+// sameValues returns true if a and b hold the same values in the same order
+func sameValues(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// changes returns the sections and items added, removed or changed from old to new, in document order
+func changes(old *Ini, new *Ini) []Change {
+	return diff(old, new, false)
+}
+
+/*
+Diff returns the differences from a to b, in document order : sections added, removed or renamed, items added, removed
+or changed with their old and new values, and comments changed
+
+A section removed from a and added to b with the same items and values is reported as renamed. Values are compared
+as loaded or set, without environment variables nor references expanded
+
+Example :
+
+	current := new(ini.Ini)
+	current.LoadFromFile("/etc/app.ini")
+	next := new(ini.Ini)
+	next.LoadFromFile("app.ini")
+
+	print(ini.SprintDiff(ini.Diff(current, next)))
+*/
+func Diff(a *Ini, b *Ini) []Change {
+	return diff(a, b, true)
+}
+
+// diff returns the changes from old to new, with the renamed sections and the comments when detailed is true
+func diff(old *Ini, new *Ini, detailed bool) []Change {
+	result := make([]Change, 0)
+
+	renamed := make(map[string]string) // old name => new name
+	if detailed {
+		matched := make(map[string]bool)
+		for _, section := range old.GetSections() {
+			if new.SectionExists(section) || len(old.GetItems(section)) == 0 {
+				continue
+			}
+			for _, candidate := range new.GetSections() {
+				if !matched[candidate] && !old.SectionExists(candidate) && sameItems(old, section, new, candidate) {
+					renamed[section] = candidate
+					matched[candidate] = true
+					break
+				}
+			}
+		}
+	}
+
+	for _, section := range old.GetSections() {
+		if newName, ok := renamed[section]; ok {
+			result = append(result, Change{Kind: SectionRenamed, Section: section, NewSection: newName})
+			result = diffSection(result, old, section, new, newName, detailed)
+			continue
+		}
+
+		if !new.SectionExists(section) {
+			result = append(result, Change{Kind: SectionRemoved, Section: section})
+			for _, item := range old.GetItems(section) {
+				result = append(result, Change{Kind: ItemRemoved, Section: section, Item: item, OldValues: old.rawValues(section, item)})
+			}
+			continue
+		}
+
+		result = diffSection(result, old, section, new, section, detailed)
+	}
+
+	renamedTo := make(map[string]bool)
+	for _, newName := range renamed {
+		renamedTo[newName] = true
+	}
+	for _, section := range new.GetSections() {
+		if !old.SectionExists(section) && !renamedTo[section] {
+			result = append(result, Change{Kind: SectionAdded, Section: section})
+			for _, item := range new.GetItems(section) {
+				result = append(result, Change{Kind: ItemAdded, Section: section, Item: item, NewValues: new.rawValues(section, item)})
+			}
+		}
+	}
+	return result
+}
+
+// diffSection appends to result the changes from the section oldName of old to the section newName of new
+func diffSection(result []Change, old *Ini, oldName string, new *Ini, newName string, detailed bool) []Change {
+	if detailed {
+		oldComments, newComments := old.GetSectionComments(oldName), new.GetSectionComments(newName)
+		if !sameValues(oldComments, newComments) {
+			result = append(result, Change{Kind: SectionCommentsChanged, Section: newName, OldComments: oldComments, NewComments: newComments})
+		}
+	}
+
+	for _, item := range old.GetItems(oldName) {
+		oldValues := old.rawValues(oldName, item)
+		if !new.ItemExists(newName, item) {
+			result = append(result, Change{Kind: ItemRemoved, Section: newName, Item: item, OldValues: oldValues})
+			continue
+		}
+		if newValues := new.rawValues(newName, item); !sameValues(oldValues, newValues) {
+			result = append(result, Change{Kind: ItemChanged, Section: newName, Item: item, OldValues: oldValues, NewValues: newValues})
+		}
+		if detailed {
+			oldComments, newComments := old.GetItemComments(oldName, item), new.GetItemComments(newName, item)
+			if !sameValues(oldComments, newComments) {
+				result = append(result, Change{Kind: ItemCommentsChanged, Section: newName, Item: item, OldComments: oldComments, NewComments: newComments})
+			}
+		}
+	}
+	for _, item := range new.GetItems(newName) {
+		if !old.ItemExists(oldName, item) {
+			result = append(result, Change{Kind: ItemAdded, Section: newName, Item: item, NewValues: new.rawValues(newName, item)})
+		}
+	}
+	return result
+}
+
+// sameItems returns true if the section aName of a and the section bName of b hold the same items and values
+func sameItems(a *Ini, aName string, b *Ini, bName string) bool {
+	items := a.GetItems(aName)
+	if len(items) != len(b.GetItems(bName)) {
+		return false
+	}
+	for _, item := range items {
+		if !b.ItemExists(bName, item) || !sameValues(a.rawValues(aName, item), b.rawValues(bName, item)) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+SprintDiff returns changes in a unified format : the header of each section involved, then the lines removed
+prefixed by "-" and the lines added prefixed by "+"
+
+Example :
+
+	 [Server]
+	-host = localhost
+	+host = 0.0.0.0
+	-[Old]
+	+[New]
+*/
+func SprintDiff(changes []Change) string {
+	var b strings.Builder
+	header := ""
+	line := func(prefix string, text string) {
+		b.WriteString(prefix + text + "\n")
+	}
+	section := func(prefix string, name string) {
+		if name != "" { // items before the first section have no header
+			line(prefix, "["+name+"]")
+		}
+	}
+	comments := func(prefix string, comments []string) {
+		for _, comment := range comments {
+			line(prefix, "; "+comment)
+		}
+	}
+	values := func(prefix string, item string, values []string) {
+		for _, value := range values {
+			line(prefix, item+" = "+value)
+		}
+	}
+
+	for _, change := range changes {
+		switch change.Kind {
+		case SectionAdded:
+			section("+", change.Section)
+			header = change.Section
+			continue
+		case SectionRemoved:
+			section("-", change.Section)
+			header = change.Section
+			continue
+		case SectionRenamed:
+			section("-", change.Section)
+			section("+", change.NewSection)
+			header = change.NewSection
+			continue
+		}
+
+		if change.Section != header {
+			section(" ", change.Section)
+			header = change.Section
+		}
+		switch change.Kind {
+		case ItemAdded:
+			values("+", change.Item, change.NewValues)
+		case ItemRemoved:
+			values("-", change.Item, change.OldValues)
+		case ItemChanged:
+			values("-", change.Item, change.OldValues)
+			values("+", change.Item, change.NewValues)
+		case SectionCommentsChanged, ItemCommentsChanged:
+			comments("-", change.OldComments)
+			comments("+", change.NewComments)
+		}
+	}
+	return b.String()
+}
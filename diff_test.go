@@ -0,0 +1,49 @@
+package ini
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	oldContent := "[Server]\n; listen address\nhost=localhost\nport=80\n[Old]\nkey=value\n[Removed]\nx=1\n"
+	newContent := "[Server]\n; listen address, all interfaces\nhost=0.0.0.0\nport=80\ntimeout=30\n[New]\nkey=value\n[Added]\ny=2\n"
+	a, b := new(Ini), new(Ini)
+	a.LoadFromString(&oldContent)
+	b.LoadFromString(&newContent)
+
+	changes := Diff(a, b)
+	expected := []Change{
+		{Kind: ItemChanged, Section: "Server", Item: "host", OldValues: []string{"localhost"}, NewValues: []string{"0.0.0.0"}},
+		{Kind: ItemCommentsChanged, Section: "Server", Item: "host", OldComments: []string{"listen address"}, NewComments: []string{"listen address, all interfaces"}},
+		{Kind: ItemAdded, Section: "Server", Item: "timeout", NewValues: []string{"30"}},
+		{Kind: SectionRenamed, Section: "Old", NewSection: "New"},
+		{Kind: SectionRemoved, Section: "Removed"},
+		{Kind: ItemRemoved, Section: "Removed", Item: "x", OldValues: []string{"1"}},
+		{Kind: SectionAdded, Section: "Added"},
+		{Kind: ItemAdded, Section: "Added", Item: "y", NewValues: []string{"2"}},
+	}
+	if len(changes) != len(expected) {
+		t.Fatal("For", "Diff", "expected", len(expected), "changes", "got", changes)
+	}
+	for i, c := range changes {
+		e := expected[i]
+		if c.Kind != e.Kind || c.Section != e.Section || c.Item != e.Item || c.NewSection != e.NewSection ||
+			!sameValues(c.OldValues, e.OldValues) || !sameValues(c.NewValues, e.NewValues) ||
+			!sameValues(c.OldComments, e.OldComments) || !sameValues(c.NewComments, e.NewComments) {
+			t.Error("For", "Diff", i, "expected", e, "got", c)
+		}
+	}
+
+	// unified rendering ///////////////////////////////////////////////////////
+	s := SprintDiff(changes)
+	expectedText := " [Server]\n-host = localhost\n+host = 0.0.0.0\n-; listen address\n+; listen address, all interfaces\n+timeout = 30\n" +
+		"-[Old]\n+[New]\n-[Removed]\n-x = 1\n+[Added]\n+y = 2\n"
+	if s != expectedText {
+		t.Error("For", "SprintDiff", "expected", expectedText, "got", s)
+	}
+
+	// no change ///////////////////////////////////////////////////////
+	if changes := Diff(a, a); len(changes) != 0 {
+		t.Error("For", "Diff(a,a)", "expected", 0, "got", changes)
+	}
+}
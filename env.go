@@ -0,0 +1,129 @@
+package ini
+
+import (
+	"os"
+	"strings"
+	"unicode"
+)
+
+/*
+EnvName returns the name of the environment variable overriding an item : section and item in upper
+case joined by "_", with every character other than a letter or a digit replaced by "_"
+
+Example :
+
+	EnvName("Server","host") // SERVER_HOST
+*/
+func EnvName(section string, item string) string {
+	name := item
+	if section != "" {
+		name = section + "_" + item
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+}
+
+/*
+MapEnv returns a function reading the environment variables from env, to set LookupEnv
+
+Example :
+
+	myIni.LookupEnv = ini.MapEnv(map[string]string{"APP_SERVER_HOST": "127.0.0.1"})
+*/
+func MapEnv(env map[string]string) func(name string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, exists := env[name]
+		return value, exists
+	}
+}
+
+// lookupEnv reads an environment variable with LookupEnv
+func (ini *Ini) lookupEnv(name string) (string, bool) {
+	if ini.LookupEnv != nil {
+		return ini.LookupEnv(name)
+	}
+	return os.LookupEnv(name)
+}
+
+// envOverride returns the value of the environment variable overriding an item, if EnvOverride is set
+func (ini *Ini) envOverride(section string, item string) (string, bool) {
+	if !ini.EnvOverride {
+		return "", false
+	}
+	name := EnvName
+	if ini.EnvNameFunc != nil {
+		name = ini.EnvNameFunc
+	}
+	return ini.lookupEnv(ini.EnvPrefix + name(section, item))
+}
+
+// isEnvNameChar returns true if c can be part of an environment variable name
+func isEnvNameChar(c byte, first bool) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || !first && c >= '0' && c <= '9'
+}
+
+// expandEnv replaces $VAR, ${VAR} and ${VAR:-default} in value, if ExpandEnv is set
+func (ini *Ini) expandEnv(value string) string {
+	if !ini.ExpandEnv || !strings.Contains(value, "$") {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' {
+			b.WriteByte(value[i])
+			continue
+		}
+		if strings.HasPrefix(value[i:], "$$") { // escaped
+			b.WriteByte('$')
+			i++
+			continue
+		}
+		text, length, _ := ini.envReference(value[i:])
+		b.WriteString(text)
+		i += length - 1
+	}
+	return b.String()
+}
+
+// envReference expands the environment variable referenced at the start of value, which starts with "$".
+// Returns the text replacing the reference, its length and true if a variable or a default value was used
+func (ini *Ini) envReference(value string) (string, int, bool) {
+	if len(value) < 2 {
+		return value, len(value), false
+	}
+
+	switch next := value[1]; {
+	case next == '{':
+		end := strings.IndexByte(value, '}')
+		if end < 0 {
+			return "$", 1, false
+		}
+		name, def := value[2:end], ""
+		hasDefault := false
+		if sep := strings.Index(name, ":-"); sep >= 0 {
+			name, def, hasDefault = name[:sep], name[sep+2:], true
+		}
+		if env, exists := ini.lookupEnv(name); exists && (env != "" || !hasDefault) {
+			return env, end + 1, true
+		} else if hasDefault {
+			return def, end + 1, true
+		}
+		return value[:end+1], end + 1, false // unknown, maybe a reference for GetExpanded
+
+	case isEnvNameChar(next, true):
+		end := 1
+		for end < len(value) && isEnvNameChar(value[end], end == 1) {
+			end++
+		}
+		if env, exists := ini.lookupEnv(value[1:end]); exists {
+			return env, end, true
+		}
+		return value[:end], end, false
+	}
+	return "$", 1, false
+}
@@ -0,0 +1,90 @@
+package ini
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnv(t *testing.T) {
+	myIni := new(Ini)
+	content := `
+[Server]
+host=localhost
+log=$HOME/logs/${APP_NAME}.log
+level=${LEVEL:-info}
+empty=${EMPTY:-default}
+ref=${paths:root} $$5 $ $UNKNOWN
+[paths]
+root=/opt
+escaped=$${root}
+`
+	myIni.LoadFromString(&content)
+	myIni.LookupEnv = MapEnv(map[string]string{
+		"HOME":            "/home/app",
+		"APP_NAME":        "demo",
+		"EMPTY":           "",
+		"APP_SERVER_HOST": "127.0.0.1",
+		"APP_NEW_ITEM":    "new",
+	})
+
+	// expansion disabled by default ///////////////////////////////////////////////////////
+	if s, _ := myIni.Get("Server", "log"); s != "$HOME/logs/${APP_NAME}.log" {
+		t.Error("For", "Get(Server,log)", "expected", "$HOME/logs/${APP_NAME}.log", "got", s)
+	}
+
+	// ExpandEnv ///////////////////////////////////////////////////////
+	myIni.ExpandEnv = true
+	tests := map[string]string{
+		"log":   "/home/app/logs/demo.log",
+		"level": "info",
+		"empty": "default",
+		"ref":   "${paths:root} $5 $ $UNKNOWN",
+	}
+	for item, expected := range tests {
+		if s, _ := myIni.Get("Server", item); s != expected {
+			t.Error("For", "Get(Server,"+item+")", "expected", expected, "got", s)
+		}
+	}
+
+	// GetExpanded unescapes $$ once ///////////////////////////////////////////////////////
+	expanded := map[string]string{
+		"Server:log":    "/home/app/logs/demo.log",
+		"Server:ref":    "/opt $5 $ $UNKNOWN",
+		"paths:escaped": "${root}",
+	}
+	for ref, expected := range expanded {
+		parts := strings.SplitN(ref, ":", 2)
+		if s, err := myIni.GetExpanded(parts[0], parts[1]); s != expected || err != nil {
+			t.Error("For", "GetExpanded("+parts[0]+","+parts[1]+")", "expected", expected, "got", s, err)
+		}
+	}
+
+	// EnvOverride ///////////////////////////////////////////////////////
+	if s, _ := myIni.Get("Server", "host"); s != "localhost" {
+		t.Error("For", "Get(Server,host)", "expected", "localhost", "got", s)
+	}
+	myIni.EnvOverride = true
+	myIni.EnvPrefix = "APP_"
+	if s, _ := myIni.Get("Server", "host"); s != "127.0.0.1" {
+		t.Error("For", "Get(Server,host)", "expected", "127.0.0.1", "got", s)
+	}
+	if s, exists := myIni.Get("new", "item"); s != "new" || !exists {
+		t.Error("For", "Get(new,item)", "expected", "new", "got", s, exists)
+	}
+	if s, err := myIni.GetExpanded("new", "item"); s != "new" || err != nil { // set only in the environment
+		t.Error("For", "GetExpanded(new,item)", "expected", "new", "got", s, err)
+	}
+	if i := myIni.GetIntDefault("Server", "port", 80); i != 80 {
+		t.Error("For", "GetIntDefault(Server,port,80)", "expected", 80, "got", i)
+	}
+
+	// EnvNameFunc ///////////////////////////////////////////////////////
+	myIni.EnvNameFunc = func(section string, item string) string { return strings.ToUpper(item) }
+	myIni.EnvPrefix = ""
+	if s, _ := myIni.Get("Server", "home"); s != "/home/app" {
+		t.Error("For", "Get(Server,home)", "expected", "/home/app", "got", s)
+	}
+	if s := EnvName("my-section", "sub.item"); s != "MY_SECTION_SUB_ITEM" {
+		t.Error("For", "EnvName(my-section,sub.item)", "expected", "MY_SECTION_SUB_ITEM", "got", s)
+	}
+}
@@ -0,0 +1,98 @@
+package ini
+
+import (
+	"sort"
+	"strings"
+)
+
+// Style tells how Format renders a document
+type Style struct {
+	// Indentation of the items and their comments
+	Indent string
+
+	// If set to true, items are written "key = value" rather than "key=value"
+	SpaceAroundEqual bool
+
+	// Marker of the comments, ";" or "#"
+	CommentMarker string
+
+	// Number of blank lines before each section, except the first one
+	BlankLines int
+
+	// Line ending, LineEndingPreserve keeps the one of the document
+	LineEnding LineEnding
+
+	// If set to true, the items of each section are sorted by name
+	SortKeys bool
+}
+
+// DefaultStyle returns the style used by go-ini fmt : no indentation, "key = value", ";" comments,
+// one blank line between sections, LF line endings and items kept in document order
+func DefaultStyle() Style {
+	return Style{SpaceAroundEqual: true, CommentMarker: ";", BlankLines: 1, LineEnding: LineEndingLF}
+}
+
+/*
+Format returns the content rendered by Sprint in the given style, with the comments and without trailing
+whitespace. The comments after the last item are kept, as are the !include and !includedir lines of a document
+loaded without ParseOptions.Includes. The other settings of ini are not changed
+
+Example :
+
+	formatted := myIni.Format(ini.DefaultStyle())
+	if formatted != original {
+		print("config.ini is not formatted\n")
+	}
+*/
+func (ini *Ini) Format(style Style) string {
+	f := ini.clone()
+	f.Lossless = false
+	f.WithComments = true
+	f.SectionPrefix = ""
+	f.ItemPrefix = style.Indent
+	f.ItemSuffix, f.ValuePrefix = "", ""
+	if style.SpaceAroundEqual {
+		f.ItemSuffix, f.ValuePrefix = " ", " "
+	}
+	f.CommentPrefix = style.CommentMarker + " "
+	f.ItemSeparator = ""
+	f.SectionSeparator = strings.Repeat("\n", style.BlankLines)
+	f.LineEnding = style.LineEnding
+	eol := f.eol()
+	f.LineEnding = LineEndingLF // the lines are trimmed before the line endings are set
+
+	if style.SortKeys {
+		for name, section := range f.data {
+			sort.Strings(section.order)
+			f.data[name] = section
+		}
+	}
+
+	f.trailing = nil // rendered below
+	content := ""
+	if f.SectionExists("") { // the items outside any section are not indented
+		top := *f
+		top.order = []string{""}
+		top.ItemPrefix = ""
+		content = top.Sprint()
+		f.order = removeName(f.order, "")
+		if len(f.order) > 0 {
+			content += f.SectionSeparator
+		}
+	}
+	content += f.Sprint()
+	for _, line := range ini.trailing { // comments and include directives after the last item
+		line = strings.TrimRight(line, "\r\n")
+		if matches := reComment.FindStringSubmatch(line); matches != nil {
+			content += f.CommentPrefix + strings.TrimSpace(matches[1]) + "\n"
+		} else if reInclude.MatchString(line) {
+			content += strings.TrimSpace(line) + "\n"
+		}
+	}
+
+	lines := strings.Split(content, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " \t")
+	}
+	return strings.Join(lines, eol)
+}
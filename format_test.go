@@ -0,0 +1,38 @@
+package ini
+
+import (
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	content := "top=1\r\n# server\r\n[Server]\r\n   port   =   80   \r\n\r\n\r\nhost=a\r\n[Empty]\r\n[Log]\r\nlevel=\r\n# level=debug\r\n"
+	myIni := new(Ini)
+	myIni.LoadFromString(&content)
+
+	expected := "top = 1\n\n; server\n[Server]\nport = 80\nhost = a\n\n[Empty]\n\n[Log]\nlevel =\n; level=debug\n"
+	if s := myIni.Format(DefaultStyle()); s != expected {
+		t.Error("For", "Format(DefaultStyle())", "expected", expected, "got", s)
+	}
+
+	style := Style{Indent: "  ", CommentMarker: "#", BlankLines: 0, LineEnding: LineEndingCRLF, SortKeys: true}
+	expected = "top=1\r\n# server\r\n[Server]\r\n  host=a\r\n  port=80\r\n[Empty]\r\n[Log]\r\n  level=\r\n# level=debug\r\n"
+	if s := myIni.Format(style); s != expected {
+		t.Error("For", "Format(style)", "expected", expected, "got", s)
+	}
+
+	// the document itself is not changed ///////////////////////////////////////////////////////
+	if items := myIni.GetItems("Server"); !sameValues(items, []string{"port", "host"}) {
+		t.Error("For", "GetItems(Server)", "expected", []string{"port", "host"}, "got", items)
+	}
+	if myIni.ItemPrefix != "  " {
+		t.Error("For", "ItemPrefix", "expected", "  ", "got", myIni.ItemPrefix)
+	}
+
+	// include directives are kept at their place ///////////////////////////////////////////////////////
+	content = "!include base.ini\n[a]\n!include mid.ini\nx=1\n!includedir conf.d\n[b]\ny=2\n!include last.ini\n"
+	myIni.LoadFromString(&content)
+	expected = "!include base.ini\n[a]\n!include mid.ini\nx = 1\n\n!includedir conf.d\n[b]\ny = 2\n!include last.ini\n"
+	if s := myIni.Format(DefaultStyle()); s != expected {
+		t.Error("For", "Format(!include)", "expected", expected, "got", s)
+	}
+}
@@ -1,34 +1,37 @@
-/*Package ini permits interaction with ini files (configuration file format for windows).
+/*
+Package ini permits interaction with ini files (configuration file format for windows).
 You can parse, read values, set values and save your ini files
 
 Basic Usage :
 
-import "github.com/ryosama/go-ini"
+	import "github.com/ryosama/go-ini"
 
-myIni := new(ini.Ini)
-if err := myIni.LoadFromFile("config.ini") ; err != nil {
-	panic("Unable to load configuration : " + err.Error())
-}
+	myIni := new(ini.Ini)
+	if err := myIni.LoadFromFile("config.ini") ; err != nil {
+		panic("Unable to load configuration : " + err.Error())
+	}
 
-myHost, _ := myIni.Get("Server","host")
+	myHost, _ := myIni.Get("Server","host")
 
-if myIni.Exists("Server","port") { // test if key exists
-	myPort := int( myIni.Get("Server","port") )
-} else {
-	myPort := 80
-}
+	myPort := myIni.GetIntDefault("Server","port",80) // 80 if the key does not exists
 
-myIni.Set("Server","host","127.0.0.1")
-myIni.Save()
+	myIni.Set("Server","host","127.0.0.1")
+	myIni.Save()
 */
 package ini
 
 import (
 	"errors"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 /*
@@ -41,6 +44,9 @@ Example :
 type Ini struct {
 	data map[string]Section
 
+	// section names in document order
+	order []string
+
 	// Last filename pass to Load or Save
 	Filename string
 
@@ -56,52 +62,353 @@ type Ini struct {
 	// Add this string before every values (default is " ")
 	ValuePrefix string
 
-	// Add this string before every new sections (except the first one) (default is "\r\n")
+	// Add this string before every new sections (except the first one) (default is "\n")
 	SectionSeparator string
 
-	// Add this string before every new item (except the first one) (default is "\r\n")
+	// Add this string before every new item (except the first one) (default is "\n")
 	ItemSeparator string
 
+	// Line ending of the written lines, also used for the line endings of SectionSeparator and ItemSeparator
+	// (default is LineEndingPreserve : the line ending of the last loaded content, "\n" if none)
+	LineEnding LineEnding
+
 	// If set to false, remove all the comments while saving (default is true)
 	WithComments bool
 
 	// Caractere(s) from prefixing a comment (default is "; ")
 	CommentPrefix string
+
+	// If set to true, items with several values are written as name[]=value instead of
+	// repeating name=value (default is false, items loaded with brackets keep them)
+	BracketArrays bool
+
+	// Permissions of a file created by Save, an existing file keeps its own (default is 0644)
+	FileMode os.FileMode
+
+	// If set to true, Save keeps the previous version of the file as Filename + ".bak" (default is false)
+	Backup bool
+
+	// If set to true, Get expands $VAR, ${VAR} and ${VAR:-default} with environment variables, unset
+	// variables without default are kept as is and $$ gives a literal $ (default is false)
+	ExpandEnv bool
+
+	// If set to true, the environment variable named EnvPrefix + EnvNameFunc(section, item) wins over
+	// the item in Get, even if the item does not exists (default is false)
+	EnvOverride bool
+
+	// Prefix of the environment variables overriding items, like "APP_" (default is "")
+	EnvPrefix string
+
+	// Returns the name of the environment variable overriding an item, without prefix (default is EnvName)
+	EnvNameFunc func(section string, item string) string
+
+	// Reads an environment variable, see MapEnv to use a map (default is os.LookupEnv)
+	LookupEnv func(name string) (string, bool)
+
+	// Maximum depth of nested references expanded by GetExpanded (default is 10)
+	MaxInterpolationDepth int
+
+	// Delay between two checks of the loaded files by Watch (default is 1 second)
+	WatchInterval time.Duration
+
+	// If set to true, Sprint keeps the original lines of the loaded content (indentation, spacing,
+	// blank lines, comment markers) and only renders the sections and items modified since (default is false)
+	Lossless bool
+
+	// If set to true, LoadFromString and LoadFromFile skip the lines which cannot be parsed
+	// and return all the errors as ParseErrors, instead of stopping at the first one (default is false)
+	CollectErrors bool
+
+	// raw lines after the last item, kept for lossless output
+	trailing []string
+
+	// constructs accepted with the Warn policy during the last load
+	warnings []*ParseError
+
+	// options of the last load and files read, used by Watch
+	options ParseOptions
+	loaded  []string
+
+	// content of the last load, checked by Lint
+	source string
+
+	// line ending of the first line of the last load, empty if none
+	detected string
+}
+
+// LineEnding is the line ending of the written lines
+type LineEnding int
+
+const (
+	// LineEndingPreserve writes the line ending detected on the first line of the last load ("\n" if none),
+	// the original lines of a lossless output keep their own line ending
+	LineEndingPreserve LineEnding = iota
+
+	// LineEndingLF writes "\n"
+	LineEndingLF
+
+	// LineEndingCRLF writes "\r\n"
+	LineEndingCRLF
+)
+
+// eol returns the line ending of the written lines
+func (ini *Ini) eol() string {
+	switch ini.LineEnding {
+	case LineEndingLF:
+		return "\n"
+	case LineEndingCRLF:
+		return "\r\n"
+	}
+	if ini.detected != "" {
+		return ini.detected
+	}
+	return "\n"
+}
+
+// withLineEnding returns s with all its line endings replaced by eol
+func withLineEnding(s string, eol string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", eol)
 }
 
 // Section has items and comments
 type Section struct {
-	items    map[string]Item
-	comments []string
+	items      map[string]Item
+	order      []string // item names in document order
+	comments   []string
+	lead       []string // raw lines before the header, nil when comments must be rendered
+	directives []string // !include and !includedir lines before the header, kept when includes are not loaded
+	raw        string   // raw header line, empty when the header must be rendered
+	position   position // where the header was read
 }
 
-// Item has value and comments
+// Item has values and comments
 type Item struct {
-	value    string
-	comments []string
+	values     []itemValue // one per line, in document order
+	comments   []string
+	lead       []string // raw lines before the item, nil when comments must be rendered
+	directives []string // !include and !includedir lines before the values, kept when includes are not loaded
+	brackets   bool     // written as name[]=value
+}
+
+// itemValue is one line of an item
+type itemValue struct {
+	text     string
+	lead     []string // raw lines between the previous value and this one
+	raw      string   // raw item line, empty when the line must be rendered
+	position position // where the line was read
+}
+
+// position is a line of a loaded file, zero when set by the program
+type position struct {
+	filename string // empty when loaded from a string
+	line     int
+}
+
+// value returns the last value of the item
+func (i Item) value() string {
+	if len(i.values) == 0 {
+		return ""
+	}
+	return i.values[len(i.values)-1].text
+}
+
+// clearLeads makes all the comments of the item rendered again
+func (i *Item) clearLeads() {
+	i.lead = nil
+	for j := range i.values {
+		i.values[j].lead = nil
+	}
+}
+
+// newValues returns the lines for the given values, keeping the original ones when unchanged and the lines
+// before a changed one
+func newValues(old []itemValue, values []string) []itemValue {
+	result := make([]itemValue, len(values))
+	for j, text := range values {
+		result[j].text = text
+		if j < len(old) && old[j].text == text {
+			result[j] = old[j]
+		} else if j < len(old) {
+			result[j].lead = old[j].lead // only the item line is rendered again
+		}
+	}
+	return result
+}
+
+var (
+	reComment = regexp.MustCompile("^\\s*[;#]\\s*(.*)$")
+	reSection = regexp.MustCompile("^\\s*\\[\\s*(.+?)\\s*\\]\\s*([;#].*)?$") // a trailing comment is allowed
+	reItem    = regexp.MustCompile("^\\s*([^=]+?)\\s*=\\s*(.*?)\\s*$")
+	reInclude = regexp.MustCompile("^\\s*!(include|includedir)\\s+(.+?)\\s*$")
+)
+
+// ParseError describes a line which cannot be parsed
+type ParseError struct {
+	// Name of the parsed file, empty when loaded from a string
+	Filename string
+
+	// Line number, starting at 1
+	Line int
+
+	// Column of the error in the line, starting at 1
+	Column int
+
+	// Offending line, without line ending
+	Text string
+
+	// Why the line cannot be parsed
+	Reason string
+}
+
+// Error returns the error as "filename:line:column: reason: text"
+func (e *ParseError) Error() string {
+	position := strconv.Itoa(e.Line) + ":" + strconv.Itoa(e.Column)
+	if e.Filename != "" {
+		position = e.Filename + ":" + position
+	}
+	return position + ": " + e.Reason + ": " + strings.TrimSpace(e.Text)
+}
+
+// ParseErrors is the list of errors returned by LoadFromString and LoadFromFile when CollectErrors is set
+type ParseErrors []*ParseError
+
+// Error returns the errors, one per line
+func (e ParseErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// newParseError explains why a line matching neither a comment, a section nor an item cannot be parsed
+func newParseError(filename string, line int, text string) *ParseError {
+	err := &ParseError{Filename: filename, Line: line, Text: text}
+	start := len(text) - len(strings.TrimLeft(text, " \t")) // first non blank character
+	trimmed := strings.TrimSpace(text)
+
+	switch {
+	case strings.HasPrefix(trimmed, "[") && !strings.Contains(trimmed, "]"):
+		err.Reason = "missing closing bracket"
+		err.Column = column(text, len(strings.TrimRight(text, " \t")))
+	case strings.HasPrefix(trimmed, "["):
+		closing := strings.Index(text, "]")
+		if strings.TrimSpace(text[start+1:closing]) == "" {
+			err.Reason = "empty section name"
+			err.Column = column(text, start)
+		} else {
+			err.Reason = "unexpected text after section header"
+			rest := text[closing+1:]
+			err.Column = column(text, closing+1+len(rest)-len(strings.TrimLeft(rest, " \t")))
+		}
+	case strings.HasPrefix(trimmed, "="):
+		err.Reason = "missing item name"
+		err.Column = column(text, start)
+	default:
+		err.Reason = "missing '=' after item name"
+		end := strings.IndexAny(text[start:], " \t")
+		if end < 0 {
+			end = len(text) - start
+		}
+		err.Column = column(text, start+end)
+	}
+	return err
+}
+
+// column converts a byte offset of text into a column number, starting at 1
+func column(text string, offset int) int {
+	return utf8.RuneCountInString(text[:offset]) + 1
+}
+
+// Policy tells the parser how to handle a questionable construct
+type Policy int
+
+const (
+	// Allow accepts the construct silently
+	Allow Policy = iota
+
+	// Warn accepts the construct and records a warning, see Warnings
+	Warn
+
+	// Reject makes the construct a parse error, the line is ignored
+	Reject
+)
+
+// ParseOptions tells the parser how to handle ambiguous content
+type ParseOptions struct {
+	// Item defined twice in the same section without brackets, all the values are kept
+	// when allowed and Get returns the last one (default is Allow)
+	DuplicateItem Policy
+
+	// Section header found twice, the items of both parts are merged (default is Allow)
+	DuplicateSection Policy
+
+	// Item before the first section header, stored in the "" section when allowed (default is Allow)
+	ItemOutsideSection Policy
+
+	// Line which is neither blank, a comment, a section header nor an item (default is Reject)
+	UnknownLine Policy
+
+	// If set to true, the directives "include = file" (before the first section header), "!include file"
+	// and "!includedir directory" load other files at their place, see LoadFromFile. Otherwise the
+	// !include and !includedir lines are kept as they are for lossless output (default is false)
+	Includes bool
+}
+
+// DefaultParseOptions returns the options used by LoadFromString and LoadFromFile
+func DefaultParseOptions() ParseOptions {
+	return ParseOptions{UnknownLine: Reject}
+}
+
+// StrictParseOptions returns options rejecting every ambiguous construct
+func StrictParseOptions() ParseOptions {
+	return ParseOptions{DuplicateItem: Reject, DuplicateSection: Reject, ItemOutsideSection: Reject, UnknownLine: Reject}
 }
 
 /*
 LoadFromFile reads ini format from a file
 
+Returns a *ParseError if a line cannot be parsed (ParseErrors if CollectErrors is set)
+
+If ParseOptions.Includes is set, the directives "include = file" (before the first section header),
+"!include file" and "!includedir directory" load other files at their place, as if their lines were
+written instead of the directive. Paths are relative to the including file and can be glob patterns,
+!includedir loads the *.ini and *.conf files of a directory. Files are loaded by name order, an include
+cycle is an error. Save writes all the items in Filename, without the directives
+
 Example :
 
 	err := myIni.LoadFromFile("config.ini")
+
+	options := ini.DefaultParseOptions()
+	options.Includes = true
+	err = myIni.LoadFromFileWithOptions("config.ini", options)
 */
 func (ini *Ini) LoadFromFile(filename string) error {
+	return ini.LoadFromFileWithOptions(filename, DefaultParseOptions())
+}
+
+/*
+LoadFromFileWithOptions reads ini format from a file, handling ambiguous content as told by options
+
+Example :
+
+	err := myIni.LoadFromFileWithOptions("config.ini", ini.StrictParseOptions())
+*/
+func (ini *Ini) LoadFromFileWithOptions(filename string, options ParseOptions) error {
 	ini.Filename = filename
 	content, err := ioutil.ReadFile(ini.Filename)
 	if err != nil {
 		return err
 	}
-	tmp := string(content)
-	ini.LoadFromString(&tmp)
-	return nil
+	return ini.parse(string(content), filename, options, osFiles)
 }
 
 /*
 LoadFromString loads data from a string pointer
 
+Returns a *ParseError if a line cannot be parsed (ParseErrors if CollectErrors is set)
+
 Example :
 
 	content := `
@@ -110,83 +417,308 @@ Example :
 
 	item1=value1`
 
-	myini.LoadFromString( &content )
+	err := myini.LoadFromString( &content )
+*/
+func (ini *Ini) LoadFromString(content *string) error {
+	return ini.LoadFromStringWithOptions(content, DefaultParseOptions())
+}
+
+/*
+LoadFromStringWithOptions loads data from a string pointer, handling ambiguous content as told by options
+
+Example :
+
+	options := ini.DefaultParseOptions()
+	options.DuplicateItem = ini.Warn
+	err := myini.LoadFromStringWithOptions( &content, options )
+	for _, warning := range myini.Warnings() {
+		print(warning.Error(), "\n")
+	}
+*/
+func (ini *Ini) LoadFromStringWithOptions(content *string, options ParseOptions) error {
+	return ini.parse(*content, "", options, osFiles)
+}
+
+/*
+Load reads ini format from r
+
+Example :
+
+	err := myIni.Load(os.Stdin)
+*/
+func (ini *Ini) Load(r io.Reader) error {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return ini.parse(string(content), "", DefaultParseOptions(), osFiles)
+}
+
+/*
+LoadFS reads ini format from the file name of fsys, like an embed.FS
+
+Example :
+
+	//go:embed config.ini
+	var configFS embed.FS
+
+	err := myIni.LoadFS(configFS, "config.ini")
 */
-func (ini *Ini) LoadFromString(content *string) {
+func (ini *Ini) LoadFS(fsys fs.FS, name string) error {
+	return ini.LoadFSWithOptions(fsys, name, DefaultParseOptions())
+}
+
+/*
+LoadFSWithOptions reads ini format from the file name of fsys, handling ambiguous content as told by options.
+The included files are read from fsys
+
+Example :
+
+	err := myIni.LoadFSWithOptions(configFS, "config.ini", ini.ParseOptions{UnknownLine: ini.Reject, Includes: true})
+*/
+func (ini *Ini) LoadFSWithOptions(fsys fs.FS, name string, options ParseOptions) error {
+	content, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+	return ini.parse(string(content), name, options, fsFiles(fsys))
+}
+
+// Warnings returns the constructs accepted with the Warn policy during the last load
+func (ini *Ini) Warnings() []*ParseError {
+	return ini.warnings
+}
+
+// parser holds the state of a load, shared with the included files
+type parser struct {
+	ini        *Ini
+	options    ParseOptions
+	files      includeFS // resolves the included files
+	stack      []string  // files being parsed, to detect include cycles
+	section    string    // active section
+	comments   []string  // comments not yet attached to a section or an item
+	lead       []string  // raw lines not yet attached to a section or an item
+	directives []string  // include directives not loaded, not yet attached to a section or an item
+	errs       ParseErrors
+}
+
+// parse loads data from content, filename is used for the errors and to resolve the included files
+func (ini *Ini) parse(content string, filename string, options ParseOptions, files includeFS) error {
 
 	// default value for formating
 	ini.WithComments = true
 	ini.CommentPrefix = "; "
-	ini.SectionSeparator = "\r\n"
-	ini.ItemSeparator = "\r\n"
+	ini.SectionSeparator = "\n"
+	ini.ItemSeparator = "\n"
 	ini.ItemPrefix = "  "
 	ini.ItemSuffix = " "
 	ini.ValuePrefix = " "
 
-	currentSection := ""
-	comments := make([]string, 0)
+	ini.data = make(map[string]Section)
+	ini.order = make([]string, 0)
+	ini.warnings = make([]*ParseError, 0)
+	ini.options = options
+	ini.loaded = make([]string, 0)
+	ini.source = content
+	ini.detected = ""
+	if i := strings.Index(content, "\n"); i > 0 && content[i-1] == '\r' {
+		ini.detected = "\r\n"
+	} else if i >= 0 {
+		ini.detected = "\n"
+	}
 
-	contentArray := strings.Split(*content, "\n") // split lines into array
+	p := &parser{
+		ini:      ini,
+		options:  options,
+		files:    files,
+		comments: make([]string, 0),
+		lead:     make([]string, 0),
+		errs:     make(ParseErrors, 0),
+	}
+	if filename != "" {
+		p.stack = []string{files.clean(filename)}
+		ini.loaded = append(ini.loaded, filename)
+	}
+	err := p.parseContent(content, filename)
+	ini.trailing = p.lead
 
-	reComment := regexp.MustCompile("\\s*[;#]\\s*(.*)")
-	reSection := regexp.MustCompile("\\s*\\[\\s*(.+?)\\s*\\]")
-	reItem := regexp.MustCompile("\\s*(.+?)\\s*=\\s*(.+)\\s*")
+	if err != nil {
+		return err
+	}
+	if len(p.errs) > 0 {
+		return p.errs
+	}
+	return nil
+}
 
-	ini.data = make(map[string]Section)
+// fail records an error, returns it if parsing must stop
+func (p *parser) fail(err *ParseError) error {
+	if !p.ini.CollectErrors {
+		return err
+	}
+	p.errs = append(p.errs, err)
+	return nil
+}
+
+// apply returns true if the line is accepted by policy, and a non nil error if parsing must stop
+func (p *parser) apply(policy Policy, filename string, lineNumber int, text string, reason string) (bool, error) {
+	if policy == Allow {
+		return true, nil
+	}
+	err := &ParseError{Filename: filename, Line: lineNumber, Text: text, Reason: reason}
+	err.Column = column(text, len(text)-len(strings.TrimLeft(text, " \t")))
+	if policy == Warn {
+		p.ini.warnings = append(p.ini.warnings, err)
+		return true, nil
+	}
+	return false, p.fail(err)
+}
+
+// parseContent parses the lines of content, read from filename
+func (p *parser) parseContent(content string, filename string) error {
+	ini := p.ini
+	options := p.options
+
+	contentArray := strings.SplitAfter(content, "\n") // split lines into array, keeping line endings
+
+	for lineNumber, raw := range contentArray { // for each line
+		if raw == "" { // nothing after the last line ending
+			continue
+		}
+		value := strings.TrimRight(raw, "\r\n")
 
-	for _, value := range contentArray { // for each line
+		if matches := reInclude.FindStringSubmatch(value); matches != nil { // !include or !includedir
+			if !options.Includes { // kept as is
+				p.lead = append(p.lead, raw)
+				p.directives = append(p.directives, strings.TrimSpace(value))
+				continue
+			}
+			if err := p.include(matches[2], matches[1] == "includedir", filename, lineNumber+1, value); err != nil {
+				return err
+			}
 
-		if matches := reComment.FindStringSubmatch(value); matches != nil { // a comment
-			comments = append(comments, strings.TrimSpace(matches[1]))
+		} else if matches := reComment.FindStringSubmatch(value); matches != nil { // a comment
+			p.comments = append(p.comments, strings.TrimSpace(matches[1]))
+			p.lead = append(p.lead, raw)
 
 		} else if matches := reSection.FindStringSubmatch(value); matches != nil { // a section
 			section := strings.TrimSpace(matches[1])
-			currentSection = section // set active section
 
-			var d Section
-			d.comments = comments
-			ini.data[currentSection] = d
-			comments = make([]string, 0) // clears comments
+			if ini.SectionExists(section) { // the items which follow are merged even if rejected
+				if _, err := p.apply(options.DuplicateSection, filename, lineNumber+1, value, "duplicate section"); err != nil {
+					return err
+				}
+			}
+			p.section = section // set active section
+
+			d, exists := ini.data[p.section]
+			if exists { // merge with the first definition, the header is kept before the next item
+				d.comments = append(d.comments, p.comments...)
+				ini.data[p.section] = d
+				p.comments = make([]string, 0)
+				p.lead = append(p.lead, raw)
+				continue
+			}
+			d.comments = p.comments
+			d.lead = p.lead
+			d.directives = p.directives
+			d.raw = raw
+			d.position = position{filename, lineNumber + 1}
+			ini.order = append(ini.order, p.section)
+			ini.data[p.section] = d
+			p.comments = make([]string, 0) // clears comments
+			p.lead = make([]string, 0)
+			p.directives = nil
 
 		} else if matches := reItem.FindStringSubmatch(value); matches != nil { // an item
 			name := strings.TrimSpace(matches[1])
-			value := strings.TrimSpace(matches[2])
+			value := unquoteValue(strings.TrimSpace(matches[2])) // quotes keep spaces and comment markers
 
-			var tmp Item
-			tmp.comments = comments
-			tmp.value = value
+			if name == "include" && p.section == "" && options.Includes { // include = file, before the first section header
+				if err := p.include(value, false, filename, lineNumber+1, matches[0]); err != nil {
+					return err
+				}
+				continue
+			}
+
+			brackets := strings.HasSuffix(name, "[]")
+			if brackets { // name[]=value adds a value to an array
+				name = strings.TrimSpace(strings.TrimSuffix(name, "[]"))
+			}
 
-			s := ini.data[currentSection]
+			accepted := true
+			var err error
+			if p.section == "" {
+				accepted, err = p.apply(options.ItemOutsideSection, filename, lineNumber+1, matches[0], "item outside any section")
+			}
+			if accepted && ini.ItemExists(p.section, name) && !(brackets && ini.data[p.section].items[name].brackets) {
+				accepted, err = p.apply(options.DuplicateItem, filename, lineNumber+1, matches[0], "duplicate item")
+			}
+			if err != nil {
+				return err
+			}
+			if !accepted {
+				p.lead = append(p.lead, raw)
+				continue
+			}
+
+			if !ini.SectionExists(p.section) { // item before the first section
+				ini.order = append(ini.order, p.section)
+			}
+			s := ini.data[p.section]
 			if s.items == nil { // create structure for the first time
 				s.items = make(map[string]Item)
 			}
+			tmp, exists := s.items[name]
+			if exists { // another value for the item
+				tmp.comments = append(tmp.comments, p.comments...)
+				tmp.directives = append(tmp.directives, p.directives...)
+				tmp.values = append(tmp.values, itemValue{text: value, lead: p.lead, raw: raw, position: position{filename, lineNumber + 1}})
+			} else {
+				tmp.comments = p.comments
+				tmp.lead = p.lead
+				tmp.directives = p.directives
+				tmp.brackets = brackets
+				tmp.values = []itemValue{{text: value, raw: raw, position: position{filename, lineNumber + 1}}}
+				s.order = append(s.order, name)
+			}
 			s.items[name] = tmp
-			ini.data[currentSection] = s
-			comments = make([]string, 0) // clears comments
-
+			ini.data[p.section] = s
+			p.comments = make([]string, 0) // clears comments
+			p.lead = make([]string, 0)
+			p.directives = nil
+
+		} else if strings.TrimSpace(value) == "" { // blank line, only kept for lossless output
+			p.lead = append(p.lead, raw)
+
+		} else { // unknown line, kept for lossless output
+			if options.UnknownLine != Reject {
+				if options.UnknownLine == Warn {
+					ini.warnings = append(ini.warnings, newParseError(filename, lineNumber+1, value))
+				}
+				p.lead = append(p.lead, raw)
+				continue
+			}
+			if err := p.fail(newParseError(filename, lineNumber+1, value)); err != nil {
+				return err
+			}
+			p.lead = append(p.lead, raw)
 		}
 	}
+	return nil
 }
 
-/*GetSections returns all the sections of the ini file */
+/*GetSections returns all the sections of the ini file, in document order */
 func (ini *Ini) GetSections() []string {
-	sections := make([]string, len(ini.data))
-	i := 0
-	for key := range ini.data {
-		sections[i] = key
-		i++
-	}
+	sections := make([]string, len(ini.order))
+	copy(sections, ini.order)
 	return sections
 }
 
-/*GetItems returns all the items of the ini file for a given section */
+/*GetItems returns all the items of the ini file for a given section, in document order */
 func (ini *Ini) GetItems(section string) []string {
-	items := make([]string, len(ini.data[section].items))
-	i := 0
-	for key := range ini.data[section].items {
-		items[i] = key
-		i++
-	}
+	items := make([]string, len(ini.data[section].order))
+	copy(items, ini.data[section].order)
 	return items
 }
 
@@ -214,33 +746,89 @@ func (ini *Ini) Exists(section string, item string) bool {
 /*
 GetItem returns the items value of the ini file for a given section and item (and true as second return value)
 
-If the item does not exists, return false as second return value
+If the item has several values, returns the last one. If the item does not exists, return false as second return value.
+A quoted value is returned without its quotes, see Quoted values in the README. See ExpandEnv and EnvOverride to use
+environment variables
 
 Example :
 
 	value, success := myini.GetItem("section1","item1")
 */
 func (ini *Ini) GetItem(section string, item string) (string, bool) {
+	if value, exists := ini.envOverride(section, item); exists {
+		return value, true
+	}
 	if ini.ItemExists(section, item) {
-		return ini.data[section].items[item].value, true
+		return ini.expandEnv(ini.data[section].items[item].value()), true
 	}
 	return "", false
 }
 
+/*
+GetAll returns all the values of an item, in document order (and true as second return value),
+return false as second return value if the item does not exists
+
+Example :
+
+	for _, server := range myIni.GetAll("upstream","server") {
+
+		print("Server ", server, "\n")
+
+	}
+*/
+func (ini *Ini) GetAll(section string, item string) ([]string, bool) {
+	if value, exists := ini.envOverride(section, item); exists {
+		return []string{value}, true
+	}
+	if ini.ItemExists(section, item) {
+		values := ini.data[section].items[item].values
+		result := make([]string, len(values))
+		for i, v := range values {
+			result[i] = ini.expandEnv(v.text)
+		}
+		return result, true
+	}
+	return make([]string, 0), false
+}
+
 /*Get is an alias for GetItem */
 func (ini *Ini) Get(section string, item string) (string, bool) {
 	return ini.GetItem(section, item)
 }
 
 /*
-SetItem sets the value of an item
+SetItem sets the value of an item, replacing all its values
 
 Returns true if success, false section or item does not exists
 */
 func (ini *Ini) SetItem(section string, item string, value string) bool {
+	return ini.SetAll(section, item, []string{value})
+}
+
+/*
+SetAll sets all the values of an item, each one is written on its own line
+
+Returns true if success, false section or item does not exists or values is empty
+*/
+func (ini *Ini) SetAll(section string, item string, values []string) bool {
+	if ini.ItemExists(section, item) && len(values) > 0 { // an item has at least one value
+		i := ini.data[section].items[item]
+		i.values = newValues(i.values, values) // changed lines must be rendered again
+		ini.data[section].items[item] = i
+		return true
+	}
+	return false
+}
+
+/*
+AddValue adds a value at the end of the values of an item
+
+Returns true if success, false section or item does not exists
+*/
+func (ini *Ini) AddValue(section string, item string, value string) bool {
 	if ini.ItemExists(section, item) {
 		i := ini.data[section].items[item]
-		i.value = value
+		i.values = append(i.values, itemValue{text: value})
 		ini.data[section].items[item] = i
 		return true
 	}
@@ -253,13 +841,22 @@ func (ini *Ini) Set(section string, item string, value string) bool {
 }
 
 /*
-RenameSection renames a section
+RenameSection renames a section, the section keeps its position
 
 Returns true if success, false if section does not exists
 */
 func (ini *Ini) RenameSection(oldName string, newName string) bool {
 	if ini.SectionExists(oldName) {
-		ini.data[newName] = ini.data[oldName]
+		if oldName == newName {
+			return true
+		}
+		if ini.SectionExists(newName) { // the renamed section replaces the existing one
+			ini.order = removeName(ini.order, newName)
+		}
+		ini.order = renameName(ini.order, oldName, newName)
+		s := ini.data[oldName]
+		s.raw = "" // the header must be rendered again
+		ini.data[newName] = s
 		delete(ini.data, oldName)
 		return true
 	}
@@ -267,14 +864,27 @@ func (ini *Ini) RenameSection(oldName string, newName string) bool {
 }
 
 /*
-RenameItem renames an item
+RenameItem renames an item, the item keeps its position
 
 Returns true if success, false if section or item does not exists
 */
 func (ini *Ini) RenameItem(section, oldName string, newName string) bool {
 	if ini.ItemExists(section, oldName) {
-		ini.data[section].items[newName] = ini.data[section].items[oldName]
-		delete(ini.data[section].items, oldName)
+		if oldName == newName {
+			return true
+		}
+		s := ini.data[section]
+		if ini.ItemExists(section, newName) { // the renamed item replaces the existing one
+			s.order = removeName(s.order, newName)
+		}
+		s.order = renameName(s.order, oldName, newName)
+		i := s.items[oldName]
+		for j := range i.values {
+			i.values[j].raw = "" // the lines must be rendered again
+		}
+		s.items[newName] = i
+		delete(s.items, oldName)
+		ini.data[section] = s
 		return true
 	}
 	return false
@@ -289,10 +899,15 @@ func (ini *Ini) AddSection(section string) bool {
 	if ini.SectionExists(section) {
 		return false
 	}
+	if ini.data == nil { // object created without loading anything
+		ini.data = make(map[string]Section)
+	}
 	var s Section
 	s.items = make(map[string]Item)
+	s.order = make([]string, 0)
 	s.comments = make([]string, 0)
 	ini.data[section] = s
+	ini.order = append(ini.order, section)
 	return true
 }
 
@@ -308,9 +923,15 @@ func (ini *Ini) AddItem(section string, item string, value string) bool {
 		}
 
 		var tmp Item
-		tmp.value = value
+		tmp.values = []itemValue{{text: value}}
 		tmp.comments = make([]string, 0)
-		ini.data[section].items[item] = tmp
+		s := ini.data[section]
+		if s.items == nil {
+			s.items = make(map[string]Item)
+		}
+		s.items[item] = tmp
+		s.order = append(s.order, item)
+		ini.data[section] = s
 
 	} else {
 		// section does not exist --> create it
@@ -329,7 +950,10 @@ func (ini *Ini) SetOrCreate(section string, item string, value string) {
 // DeleteItem deletes an item, return true if succes, false if the item does not exists
 func (ini *Ini) DeleteItem(section string, item string) bool {
 	if ini.ItemExists(section, item) {
-		delete(ini.data[section].items, item)
+		s := ini.data[section]
+		delete(s.items, item)
+		s.order = removeName(s.order, item)
+		ini.data[section] = s
 		return true
 	}
 	return false
@@ -339,6 +963,7 @@ func (ini *Ini) DeleteItem(section string, item string) bool {
 func (ini *Ini) DeleteSection(section string) bool {
 	if ini.SectionExists(section) {
 		delete(ini.data, section)
+		ini.order = removeName(ini.order, section)
 		return true
 	}
 	return false
@@ -385,6 +1010,7 @@ func (ini *Ini) AddItemComment(section string, item string, comment string) bool
 	if ini.SectionExists(section) && ini.ItemExists(section, item) {
 		tmp := ini.data[section].items[item]
 		tmp.comments = append(tmp.comments, comment) // add the comment
+		tmp.clearLeads()                             // comments must be rendered again
 		ini.data[section].items[item] = tmp
 		return true
 	}
@@ -396,6 +1022,7 @@ func (ini *Ini) DeleteItemComments(section string, item string) bool {
 	if ini.SectionExists(section) && ini.ItemExists(section, item) {
 		tmp := ini.data[section].items[item]
 		tmp.comments = make([]string, 0) // clear comments
+		tmp.clearLeads()
 		ini.data[section].items[item] = tmp
 		return true
 	}
@@ -422,6 +1049,7 @@ func (ini *Ini) AddSectionComment(section string, comment string) bool {
 	if ini.SectionExists(section) {
 		tmp := ini.data[section]
 		tmp.comments = append(tmp.comments, comment) // add the comment
+		tmp.lead = nil                               // comments must be rendered again
 		ini.data[section] = tmp
 		return true
 	}
@@ -433,6 +1061,7 @@ func (ini *Ini) DeleteSectionComments(section string) bool {
 	if ini.SectionExists(section) {
 		tmp := ini.data[section]
 		tmp.comments = make([]string, 0) // clear comments
+		tmp.lead = nil
 		ini.data[section] = tmp
 		return true
 	}
@@ -454,9 +1083,36 @@ func (ini *Ini) DeleteSectionComment(section string, id int) bool {
 	return false
 }
 
+// removeName returns names without the given name
+func removeName(names []string, name string) []string {
+	result := make([]string, 0, len(names))
+	for _, n := range names {
+		if n != name {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// renameName returns names with oldName replaced by newName at the same position
+func renameName(names []string, oldName string, newName string) []string {
+	result := make([]string, len(names))
+	for i, n := range names {
+		if n == oldName {
+			n = newName
+		}
+		result[i] = n
+	}
+	return result
+}
+
 /*
 Save saves the ini format to a file
 
+The content is written to a temporary file of the same directory, synced and renamed over the
+original file, so the file is never left half written. An existing file keeps its permissions and
+owner, a new one is created with FileMode
+
 Example :
 
 	err := myIni.Save() // use myIni.Filename to save
@@ -471,58 +1127,278 @@ func (ini *Ini) Save(params ...string) error {
 	if ini.Filename == "" {
 		return errors.New("You must specify a filename before saving")
 	}
-	return ioutil.WriteFile(ini.Filename, []byte(ini.Sprint()), os.ModePerm)
+
+	filename := ini.Filename
+	if target, err := filepath.EvalSymlinks(filename); err == nil { // replace the target, not the link
+		filename = target
+	}
+
+	mode := ini.FileMode
+	if mode == 0 {
+		mode = 0644
+	}
+	info, err := os.Stat(filename)
+	exists := err == nil
+	if exists {
+		mode = info.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), "."+filepath.Base(filename)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails once renamed
+
+	err = tmp.Chmod(mode)
+	if err == nil && exists {
+		err = chown(tmp, info)
+	}
+	if err == nil {
+		_, err = ini.WriteTo(tmp)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if ini.Backup && exists {
+		if err := copyFile(filename, filename+".bak", mode); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return err
+	}
+
+	if dir, err := os.Open(filepath.Dir(filename)); err == nil { // persist the rename
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
+
+// copyFile copies the content of src to dst, created with mode
+func copyFile(src string, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
 }
 
 /*
 Sprint returns the ini format into a formatted string
 
 TIPS :
-You can set SectionPrefix,ItemPrefix, ItemSuffix, ValuePrefix, SectionSeparator, ItemSeparator, WithComments, CommentPrefix to tweak format aspect
+You can set SectionPrefix,ItemPrefix, ItemSuffix, ValuePrefix, SectionSeparator, ItemSeparator, LineEnding, WithComments, CommentPrefix to tweak format aspect
 */
 func (ini *Ini) Sprint() string {
-	cr := "\r\n"
-	s := ""
+	var b strings.Builder
+	ini.WriteTo(&b) // a strings.Builder never fails
+	return b.String()
+}
+
+// lineWriter writes to w, counting the bytes and keeping the first error
+type lineWriter struct {
+	w    io.Writer
+	n    int64
+	err  error
+	last byte // last byte written
+}
+
+// write writes s, does nothing after an error
+func (lw *lineWriter) write(s string) {
+	if lw.err != nil || s == "" {
+		return
+	}
+	n, err := io.WriteString(lw.w, s)
+	lw.n += int64(n)
+	lw.err = err
+	lw.last = s[len(s)-1]
+}
+
+/*
+WriteTo writes the ini format to w, it implements io.WriterTo
+
+Example :
+
+	_, err := myIni.WriteTo(os.Stdout)
+*/
+func (ini *Ini) WriteTo(w io.Writer) (int64, error) {
+	lw := &lineWriter{w: w}
+	if ini.Lossless {
+		ini.writeLossless(lw)
+	} else {
+		ini.write(lw)
+	}
+	return lw.n, lw.err
+}
+
+// write renders all the sections and items
+func (ini *Ini) write(lw *lineWriter) {
+	cr := ini.eol()
 
 	sections := ini.GetSections()
 	for i := 0; i < len(sections); i++ {
+		for _, directive := range ini.data[sections[i]].directives { // include directives are not comments
+			lw.write(directive + cr)
+		}
 		if ini.WithComments { // add the sections comments
 			for _, com := range ini.GetSectionComments(sections[i]) {
-				s += ini.SectionPrefix + ini.CommentPrefix + com + cr
+				lw.write(ini.SectionPrefix + ini.CommentPrefix + com + cr)
 			}
 		}
 
-		s += ini.SectionPrefix + "[" + sections[i] + "]" + cr
+		if sections[i] != "" { // items before the first section have no header
+			lw.write(ini.SectionPrefix + "[" + sections[i] + "]" + cr)
+		}
 
 		items := ini.GetItems(sections[i])
 		for j := 0; j < len(items); j++ {
+			for _, directive := range ini.data[sections[i]].items[items[j]].directives {
+				lw.write(directive + cr)
+			}
 			if ini.WithComments { // add the item comments
 				for _, com := range ini.GetItemComments(sections[i], items[j]) {
-					s += ini.ItemPrefix + ini.CommentPrefix + com + cr
+					lw.write(ini.ItemPrefix + ini.CommentPrefix + com + cr)
 				}
 			}
 
-			value, _ := ini.GetItem(sections[i], items[j])
-			s += ini.ItemPrefix + items[j] + ini.ItemSuffix + "=" + ini.ValuePrefix + value + cr
+			it := ini.data[sections[i]].items[items[j]]
+			for _, v := range it.values { // one line per value
+				lw.write(ini.sprintValue(items[j], it, v.text) + cr)
+			}
 
 			if j != len(items)-1 {
-				s += ini.ItemSeparator
+				lw.write(withLineEnding(ini.ItemSeparator, cr))
 			}
+		}
 
-			if j == len(items)-1 && i != len(sections)-1 { // add section separator if last item
-				s += ini.SectionSeparator
+		if i != len(sections)-1 { // add section separator, even after an empty section
+			lw.write(withLineEnding(ini.SectionSeparator, cr))
+		}
+	}
+
+	for _, line := range ini.trailing { // include directives after the last item
+		if line = strings.TrimSpace(line); reInclude.MatchString(line) {
+			lw.write(line + cr)
+		}
+	}
+}
+
+// sprintValue returns the rendered line of one value of an item, without line ending
+func (ini *Ini) sprintValue(name string, item Item, value string) string {
+	if item.brackets || (ini.BracketArrays && len(item.values) > 1) {
+		name += "[]"
+	}
+	return ini.ItemPrefix + name + ini.ItemSuffix + "=" + ini.ValuePrefix + quoteValue(value)
+}
+
+// writeLossless writes the original lines of the unmodified sections and items, and renders the others
+func (ini *Ini) writeLossless(lw *lineWriter) {
+	cr := ini.eol()
+
+	// write adds a line, making sure the previous one is terminated
+	write := func(line string) {
+		if ini.LineEnding != LineEndingPreserve { // the original lines too
+			line = withLineEnding(line, cr)
+		}
+		if lw.n > 0 && lw.last != '\n' {
+			lw.write(cr)
+		}
+		lw.write(line)
+	}
+
+	// writeLead adds raw lines, without the comments if they are disabled
+	writeLead := func(lead []string) {
+		for _, line := range lead {
+			if ini.WithComments || !reComment.MatchString(strings.TrimRight(line, "\r\n")) {
+				write(line)
 			}
 		}
 	}
 
-	return s
+	for i, section := range ini.order {
+		sec := ini.data[section]
+
+		if sec.lead != nil {
+			writeLead(sec.lead)
+		} else {
+			if sec.raw == "" && i > 0 { // new section
+				write(withLineEnding(ini.SectionSeparator, cr))
+			}
+			for _, directive := range sec.directives {
+				write(directive + cr)
+			}
+			if ini.WithComments {
+				for _, com := range sec.comments {
+					write(ini.SectionPrefix + ini.CommentPrefix + com + cr)
+				}
+			}
+		}
+
+		if sec.raw != "" {
+			write(sec.raw)
+		} else if section != "" {
+			write(ini.SectionPrefix + "[" + section + "]" + cr)
+		}
+
+		for _, item := range sec.order {
+			it := sec.items[item]
+
+			if it.lead != nil {
+				writeLead(it.lead)
+			} else {
+				for _, directive := range it.directives {
+					write(directive + cr)
+				}
+				if ini.WithComments {
+					for _, com := range it.comments {
+						write(ini.ItemPrefix + ini.CommentPrefix + com + cr)
+					}
+				}
+			}
+
+			for j, v := range it.values {
+				if j > 0 {
+					writeLead(v.lead)
+				}
+				if v.raw != "" {
+					write(v.raw)
+				} else {
+					write(ini.sprintValue(item, it, v.text) + cr)
+				}
+			}
+		}
+	}
+	writeLead(ini.trailing)
 }
 
 /*
 Print prints the ini format into a formatted string
 
 TIPS :
-You can set SectionPrefix,ItemPrefix, ItemSuffix, ValuePrefix, SectionSeparator, ItemSeparator, WithComments, CommentPrefix to tweak format aspect
+You can set SectionPrefix,ItemPrefix, ItemSuffix, ValuePrefix, SectionSeparator, ItemSeparator, LineEnding, WithComments, CommentPrefix to tweak format aspect
 */
 func (ini *Ini) Print() {
 	print(ini.Sprint())
@@ -1,9 +1,15 @@
 package ini
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sort"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func Test(t *testing.T) {
@@ -324,3 +330,368 @@ func Test(t *testing.T) {
 		t.Error("For", "GetSectionComments(rename section)", "expected", exceptedComments, "got", s)
 	}
 }
+
+func TestOrder(t *testing.T) {
+	var s string
+
+	myIni := new(Ini)
+	content := `
+[zeta]
+b=1
+a=2
+c=3
+[alpha]
+y=1
+x=2
+[middle]
+k=v
+`
+	myIni.LoadFromString(&content)
+
+	// GetSections ///////////////////////////////////////////////////////
+	s = fmt.Sprintf("%v", myIni.GetSections())
+	expected := fmt.Sprintf("%v", []string{"zeta", "alpha", "middle"})
+	if s != expected {
+		t.Error("For", "GetSections()", "expected", expected, "got", s)
+	}
+
+	// GetItems ///////////////////////////////////////////////////////
+	s = fmt.Sprintf("%v", myIni.GetItems("zeta"))
+	expected = fmt.Sprintf("%v", []string{"b", "a", "c"})
+	if s != expected {
+		t.Error("For", "GetItems(zeta)", "expected", expected, "got", s)
+	}
+
+	// RenameSection keeps the position ///////////////////////////////////////////////////////
+	myIni.RenameSection("alpha", "beta")
+	s = fmt.Sprintf("%v", myIni.GetSections())
+	expected = fmt.Sprintf("%v", []string{"zeta", "beta", "middle"})
+	if s != expected {
+		t.Error("For", "RenameSection(alpha,beta)", "expected", expected, "got", s)
+	}
+
+	// RenameItem keeps the position ///////////////////////////////////////////////////////
+	myIni.RenameItem("zeta", "a", "aa")
+	s = fmt.Sprintf("%v", myIni.GetItems("zeta"))
+	expected = fmt.Sprintf("%v", []string{"b", "aa", "c"})
+	if s != expected {
+		t.Error("For", "RenameItem(zeta,a,aa)", "expected", expected, "got", s)
+	}
+
+	// AddSection, AddItem append at the end ///////////////////////////////////////////////////////
+	myIni.AddItem("zeta", "0", "added")
+	myIni.AddItem("new", "n", "added")
+	myIni.DeleteItem("zeta", "b")
+	myIni.DeleteSection("middle")
+	s = fmt.Sprintf("%v %v", myIni.GetSections(), myIni.GetItems("zeta"))
+	expected = fmt.Sprintf("%v %v", []string{"zeta", "beta", "new"}, []string{"aa", "c", "0"})
+	if s != expected {
+		t.Error("For", "AddItem/DeleteItem", "expected", expected, "got", s)
+	}
+
+	// Sprint is stable ///////////////////////////////////////////////////////
+	myIni.ItemPrefix = ""
+	myIni.ItemSuffix = ""
+	myIni.ValuePrefix = ""
+	myIni.SectionSeparator = ""
+	myIni.ItemSeparator = ""
+	s = myIni.Sprint()
+	expected = "[zeta]\naa=2\nc=3\n0=added\n[beta]\ny=1\nx=2\n[new]\nn=added\n"
+	if s != expected {
+		t.Error("For", "Sprint()", "expected", expected, "got", s)
+	}
+}
+
+func TestLossless(t *testing.T) {
+	var s string
+
+	myIni := new(Ini)
+	myIni.Lossless = true
+	content := "# header comment\n\n  top = level\n[ first ]\r\n\tkey1   =   value1\n; note for key2\n   key2=value2\n\n[second]\nkeep = me\n# trailing comment\n"
+	myIni.LoadFromString(&content)
+
+	// untouched content ///////////////////////////////////////////////////////
+	if s = myIni.Sprint(); s != content {
+		t.Error("For", "Sprint()", "expected", content, "got", s)
+	}
+
+	// only the modified lines are rendered ///////////////////////////////////////////////////////
+	myIni.Set("first", "key2", "changed")
+	myIni.AddItemComment("second", "keep", "new comment")
+	myIni.AddItem("second", "added", "value")
+	myIni.RenameSection("second", "2nd")
+	expected := "# header comment\n\n  top = level\n[ first ]\r\n\tkey1   =   value1\n; note for key2\n  key2 = changed\n\n[2nd]\n  ; new comment\nkeep = me\n  added = value\n# trailing comment\n"
+	if s = myIni.Sprint(); s != expected {
+		t.Errorf("For Sprint() expected %q got %q", expected, s)
+	}
+
+	// deleted lines disappear with their comments ///////////////////////////////////////////////////////
+	myIni.DeleteItem("first", "key2")
+	expected = "# header comment\n\n  top = level\n[ first ]\r\n\tkey1   =   value1\n\n[2nd]\n  ; new comment\nkeep = me\n  added = value\n# trailing comment\n"
+	if s = myIni.Sprint(); s != expected {
+		t.Errorf("For Sprint() expected %q got %q", expected, s)
+	}
+
+	// the original lines take the line ending set ///////////////////////////////////////////////////////
+	myIni.LineEnding = LineEndingCRLF
+	expected = "# header comment\r\n\r\n  top = level\r\n[ first ]\r\n\tkey1   =   value1\r\n\r\n[2nd]\r\n  ; new comment\r\nkeep = me\r\n  added = value\r\n# trailing comment\r\n"
+	if s = myIni.Sprint(); s != expected {
+		t.Errorf("For Sprint() expected %q got %q", expected, s)
+	}
+	// the lines before a changed value are kept ///////////////////////////////////////////////////////
+	myIni = new(Ini)
+	myIni.Lossless = true
+	content = "[a]\nx=1\n; about two\nx=2\n"
+	myIni.LoadFromString(&content)
+	myIni.SetAll("a", "x", []string{"1", "3"})
+	expected = "[a]\nx=1\n; about two\n  x = 3\n"
+	if s = myIni.Sprint(); s != expected {
+		t.Errorf("For Sprint() expected %q got %q", expected, s)
+	}
+}
+
+func TestLineEnding(t *testing.T) {
+	tests := []struct {
+		content    string
+		lineEnding LineEnding
+		expected   string
+	}{
+		{"[a]\r\nx=1\r\n[b]\ny=2\n", LineEndingPreserve, "[a]\r\n  x = 1\r\n\r\n[b]\r\n  y = 2\r\n"}, // detected on the first line
+		{"[a]\nx=1\n[b]\r\ny=2\r\n", LineEndingPreserve, "[a]\n  x = 1\n\n[b]\n  y = 2\n"},
+		{"", LineEndingPreserve, "[a]\n  x = 1\n\n[b]\n  y = 2\n"},
+		{"[a]\r\nx=1\r\n[b]\r\ny=2\r\n", LineEndingLF, "[a]\n  x = 1\n\n[b]\n  y = 2\n"},
+		{"[a]\nx=1\n[b]\ny=2\n", LineEndingCRLF, "[a]\r\n  x = 1\r\n\r\n[b]\r\n  y = 2\r\n"},
+	}
+	for _, test := range tests {
+		myIni := new(Ini)
+		content := test.content
+		myIni.LoadFromString(&content)
+		myIni.SetOrCreate("a", "x", "1")
+		myIni.SetOrCreate("b", "y", "2")
+		myIni.LineEnding = test.lineEnding
+		if s := myIni.Sprint(); s != test.expected {
+			t.Errorf("For Sprint(%q) expected %q got %q", test.content, test.expected, s)
+		}
+	}
+
+	// the separators take the line ending too ///////////////////////////////////////////////////////
+	myIni := new(Ini)
+	content := "[a]\r\nx=1\r\n[b]\r\ny=2\r\n"
+	myIni.LoadFromString(&content)
+	myIni.SectionSeparator = "\n;----\n"
+	if s, expected := myIni.Sprint(), "[a]\r\n  x = 1\r\n\r\n;----\r\n[b]\r\n  y = 2\r\n"; s != expected {
+		t.Errorf("For Sprint() expected %q got %q", expected, s)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	var s string
+
+	myIni := new(Ini)
+	content := "[ok]\nitem=value\n[section\n"
+	err := myIni.LoadFromString(&content)
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatal("For", "LoadFromString([section)", "expected", "*ParseError", "got", err)
+	}
+	s = fmt.Sprintf("%d %d %s %s", perr.Line, perr.Column, perr.Text, perr.Reason)
+	expected := "3 9 [section missing closing bracket"
+	if s != expected {
+		t.Error("For", "LoadFromString([section)", "expected", expected, "got", s)
+	}
+
+	// CollectErrors ///////////////////////////////////////////////////////
+	myIni = new(Ini)
+	myIni.CollectErrors = true
+	content = "key value\n[]\n[a] b\n=value\n[a]\nok=1\n[db] ; primary\nhost=h\n"
+	err = myIni.LoadFromString(&content)
+	errs, ok := err.(ParseErrors)
+	if !ok {
+		t.Fatal("For", "LoadFromString()", "expected", "ParseErrors", "got", err)
+	}
+	s = errs.Error()
+	expected = "1:4: missing '=' after item name: key value\n" +
+		"2:1: empty section name: []\n" +
+		"3:5: unexpected text after section header: [a] b\n" +
+		"4:1: missing item name: =value"
+	if s != expected {
+		t.Error("For", "ParseErrors.Error()", "expected", expected, "got", s)
+	}
+	if s, _ = myIni.Get("a", "ok"); s != "1" {
+		t.Error("For", "Get(a,ok)", "expected", "1", "got", s)
+	}
+	if s, _ = myIni.Get("db", "host"); s != "h" { // a comment after a section header is allowed
+		t.Error("For", "Get(db,host)", "expected", "h", "got", s)
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	var s string
+	content := "outside=1\n[a]\nx=1\nx=2\n[b]\ny=1\n[a]\nz=1\n"
+
+	// default options accept everything ///////////////////////////////////////////////////////
+	myIni := new(Ini)
+	if err := myIni.LoadFromString(&content); err != nil {
+		t.Error("For", "LoadFromString()", "expected", nil, "got", err)
+	}
+	s = fmt.Sprintf("%v", myIni.GetItems("a"))
+	if expected := "[x z]"; s != expected {
+		t.Error("For", "GetItems(a)", "expected", expected, "got", s)
+	}
+	if s, _ = myIni.Get("a", "x"); s != "2" {
+		t.Error("For", "Get(a,x)", "expected", "2", "got", s)
+	}
+
+	// Warn ///////////////////////////////////////////////////////
+	options := ParseOptions{DuplicateItem: Warn, DuplicateSection: Warn, ItemOutsideSection: Warn}
+	if err := myIni.LoadFromStringWithOptions(&content, options); err != nil {
+		t.Error("For", "LoadFromStringWithOptions(Warn)", "expected", nil, "got", err)
+	}
+	warnings := make([]string, 0)
+	for _, w := range myIni.Warnings() {
+		warnings = append(warnings, w.Error())
+	}
+	s = fmt.Sprintf("%q", warnings)
+	expected := fmt.Sprintf("%q", []string{"1:1: item outside any section: outside=1", "4:1: duplicate item: x=2", "7:1: duplicate section: [a]"})
+	if s != expected {
+		t.Error("For", "Warnings()", "expected", expected, "got", s)
+	}
+
+	// Reject ///////////////////////////////////////////////////////
+	myIni = new(Ini)
+	myIni.CollectErrors = true
+	err := myIni.LoadFromStringWithOptions(&content, StrictParseOptions())
+	if errs, ok := err.(ParseErrors); !ok || len(errs) != 3 {
+		t.Error("For", "LoadFromStringWithOptions(Strict)", "expected", "3 errors", "got", err)
+	}
+	if s, _ = myIni.Get("a", "x"); s != "1" {
+		t.Error("For", "Get(a,x)", "expected", "1", "got", s)
+	}
+	if s = fmt.Sprintf("%v", myIni.GetItems("a")); s != "[x z]" {
+		t.Error("For", "GetItems(a)", "expected", "[x z]", "got", s)
+	}
+	if b := myIni.Exists("", "outside"); b {
+		t.Error("For", "Exists(,outside)", "expected", false, "got", b)
+	}
+}
+
+func TestMultipleValues(t *testing.T) {
+	var s string
+	var b bool
+
+	myIni := new(Ini)
+	content := "[upstream]\nserver=a\nserver=b\nhost[]=x\nhost[]=y\n"
+	myIni.LoadFromString(&content)
+
+	// GetAll ///////////////////////////////////////////////////////
+	a, _ := myIni.GetAll("upstream", "server")
+	s = fmt.Sprintf("%v", a)
+	if expected := "[a b]"; s != expected {
+		t.Error("For", "GetAll(upstream,server)", "expected", expected, "got", s)
+	}
+	a, _ = myIni.GetAll("upstream", "host")
+	s = fmt.Sprintf("%v", a)
+	if expected := "[x y]"; s != expected {
+		t.Error("For", "GetAll(upstream,host)", "expected", expected, "got", s)
+	}
+	// Get returns the last value
+	if s, _ = myIni.Get("upstream", "server"); s != "b" {
+		t.Error("For", "Get(upstream,server)", "expected", "b", "got", s)
+	}
+
+	// AddValue, SetAll ///////////////////////////////////////////////////////
+	if b = myIni.AddValue("upstream", "server", "c"); !b {
+		t.Error("For", "AddValue(upstream,server,c)", "expected", true, "got", b)
+	}
+	if b = myIni.SetAll("upstream", "host", []string{"z"}); !b {
+		t.Error("For", "SetAll(upstream,host,[z])", "expected", true, "got", b)
+	}
+	if b = myIni.SetAll("upstream", "host", []string{}); b { // an item keeps at least one value
+		t.Error("For", "SetAll(upstream,host,[])", "expected", false, "got", b)
+	}
+	if b = myIni.AddValue("upstream", "does not exists", "c"); b {
+		t.Error("For", "AddValue(upstream,does not exists,c)", "expected", false, "got", b)
+	}
+
+	// Sprint one line per value ///////////////////////////////////////////////////////
+	myIni.ItemPrefix = ""
+	myIni.ItemSuffix = ""
+	myIni.ValuePrefix = ""
+	myIni.ItemSeparator = ""
+	expected := "[upstream]\nserver=a\nserver=b\nserver=c\nhost[]=z\n"
+	if s = myIni.Sprint(); s != expected {
+		t.Error("For", "Sprint()", "expected", expected, "got", s)
+	}
+	myIni.BracketArrays = true
+	expected = "[upstream]\nserver[]=a\nserver[]=b\nserver[]=c\nhost[]=z\n"
+	if s = myIni.Sprint(); s != expected {
+		t.Error("For", "Sprint()", "expected", expected, "got", s)
+	}
+}
+
+func TestReaderWriter(t *testing.T) {
+	var s string
+
+	myIni := new(Ini)
+	if err := myIni.Load(strings.NewReader("[a]\nb=c\n")); err != nil {
+		t.Error("For", "Load()", "expected", nil, "got", err)
+	}
+	if s, _ = myIni.Get("a", "b"); s != "c" {
+		t.Error("For", "Get(a,b)", "expected", "c", "got", s)
+	}
+
+	// LoadFS ///////////////////////////////////////////////////////
+	fsys := fstest.MapFS{"conf/app.ini": &fstest.MapFile{Data: []byte("[fs]\nkey=value\n[broken\n")}}
+	err := myIni.LoadFS(fsys, "conf/app.ini")
+	if perr, ok := err.(*ParseError); !ok || perr.Filename != "conf/app.ini" || perr.Line != 3 {
+		t.Error("For", "LoadFS(conf/app.ini)", "expected", "a *ParseError at conf/app.ini:3", "got", err)
+	}
+	if s, _ = myIni.Get("fs", "key"); s != "value" {
+		t.Error("For", "Get(fs,key)", "expected", "value", "got", s)
+	}
+
+	// WriteTo ///////////////////////////////////////////////////////
+	var b bytes.Buffer
+	n, err := myIni.WriteTo(&b)
+	if s = b.String(); s != myIni.Sprint() || n != int64(len(s)) || err != nil {
+		t.Error("For", "WriteTo()", "expected", myIni.Sprint(), "got", s, n, err)
+	}
+}
+
+func TestSave(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.ini")
+
+	// new file uses FileMode ///////////////////////////////////////////////////////
+	myIni := new(Ini)
+	myIni.SetOrCreate("a", "b", "c")
+	if err := myIni.Save(filename); err != nil {
+		t.Fatal("For", "Save(config.ini)", "expected", nil, "got", err)
+	}
+	if info, _ := os.Stat(filename); info.Mode().Perm() != 0644 {
+		t.Error("For", "Save(config.ini)", "expected", os.FileMode(0644), "got", info.Mode().Perm())
+	}
+
+	// existing file keeps its mode, backup ///////////////////////////////////////////////////////
+	os.Chmod(filename, 0600)
+	previous, _ := ioutil.ReadFile(filename)
+	myIni.Set("a", "b", "changed")
+	myIni.Backup = true
+	if err := myIni.Save(); err != nil {
+		t.Fatal("For", "Save()", "expected", nil, "got", err)
+	}
+	if info, _ := os.Stat(filename); info.Mode().Perm() != 0600 {
+		t.Error("For", "Save()", "expected", os.FileMode(0600), "got", info.Mode().Perm())
+	}
+	if backup, _ := ioutil.ReadFile(filename + ".bak"); string(backup) != string(previous) {
+		t.Error("For", "Save() backup", "expected", string(previous), "got", string(backup))
+	}
+	if content, _ := ioutil.ReadFile(filename); string(content) != myIni.Sprint() {
+		t.Error("For", "Save()", "expected", myIni.Sprint(), "got", string(content))
+	}
+
+	// no temporary file left ///////////////////////////////////////////////////////
+	if files, _ := ioutil.ReadDir(dir); len(files) != 2 {
+		t.Error("For", "Save()", "expected", 2, "files got", len(files))
+	}
+}
@@ -0,0 +1,140 @@
+package ini
+
+import (
+	"io/fs"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// includeFS resolves and reads the included files
+type includeFS interface {
+	readFile(name string) ([]byte, error)
+	glob(pattern string) ([]string, error)
+	join(dir string, name string) string // name relative to dir, unless absolute
+	dir(name string) string
+	clean(name string) string // unique name, to detect include cycles
+}
+
+// osFiles reads the included files from the disk
+var osFiles includeFS = diskFiles{}
+
+type diskFiles struct{}
+
+func (diskFiles) readFile(name string) ([]byte, error)  { return ioutil.ReadFile(name) }
+func (diskFiles) glob(pattern string) ([]string, error) { return filepath.Glob(pattern) }
+func (diskFiles) dir(name string) string                { return filepath.Dir(name) }
+
+func (diskFiles) join(dir string, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(dir, name)
+}
+
+func (diskFiles) clean(name string) string {
+	if abs, err := filepath.Abs(name); err == nil {
+		return abs
+	}
+	return filepath.Clean(name)
+}
+
+// fsFiles reads the included files from fsys
+func fsFiles(fsys fs.FS) includeFS {
+	return fsysFiles{fsys}
+}
+
+type fsysFiles struct {
+	fsys fs.FS
+}
+
+func (f fsysFiles) readFile(name string) ([]byte, error)  { return fs.ReadFile(f.fsys, name) }
+func (f fsysFiles) glob(pattern string) ([]string, error) { return fs.Glob(f.fsys, pattern) }
+func (fsysFiles) dir(name string) string                  { return path.Dir(name) }
+func (fsysFiles) clean(name string) string                { return path.Clean(name) }
+
+func (fsysFiles) join(dir string, name string) string {
+	if strings.HasPrefix(name, "/") { // fs.FS names are relative to its root
+		return path.Clean(strings.TrimPrefix(name, "/"))
+	}
+	return path.Join(dir, name)
+}
+
+/*
+include loads the files matching target at the place of the directive, as if their lines were written
+instead of it : an included file can add items to the active section and change it
+
+target is relative to the directory of the including file (the current directory when loaded from
+a string), it can be a glob pattern. With directory, all the *.ini and *.conf files of the target
+directory are loaded. Files are loaded by name order
+*/
+func (p *parser) include(target string, directory bool, filename string, lineNumber int, text string) error {
+	dir := "."
+	if filename != "" {
+		dir = p.files.dir(filename)
+	}
+	pattern := p.files.join(dir, target)
+
+	// includeError makes a ParseError for the directive
+	includeError := func(reason string) *ParseError {
+		err := &ParseError{Filename: filename, Line: lineNumber, Text: text, Reason: reason}
+		err.Column = column(text, len(text)-len(strings.TrimLeft(text, " \t")))
+		return err
+	}
+
+	var names []string
+	if directory {
+		for _, extension := range []string{"*.ini", "*.conf"} {
+			matches, err := p.files.glob(p.files.join(pattern, extension))
+			if err != nil {
+				return p.fail(includeError("cannot include directory: " + err.Error()))
+			}
+			names = append(names, matches...)
+		}
+	} else if strings.ContainsAny(target, "*?[") {
+		matches, err := p.files.glob(pattern)
+		if err != nil {
+			return p.fail(includeError("cannot include: " + err.Error()))
+		}
+		names = matches
+	} else {
+		names = []string{pattern}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		clean := p.files.clean(name)
+		cycle := false
+		for i, parent := range p.stack {
+			if parent == clean {
+				cycle = true
+				chain := append(append([]string{}, p.stack[i:]...), clean)
+				if err := p.fail(includeError("include cycle " + strings.Join(chain, " -> "))); err != nil {
+					return err
+				}
+			}
+		}
+		if cycle {
+			continue
+		}
+
+		content, err := p.files.readFile(name)
+		if err != nil {
+			if err := p.fail(includeError("cannot include: " + err.Error())); err != nil {
+				return err
+			}
+			continue
+		}
+
+		p.ini.loaded = append(p.ini.loaded, name)
+		p.stack = append(p.stack, clean)
+		err = p.parseContent(string(content), name)
+		p.stack = p.stack[:len(p.stack)-1]
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package ini
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestInclude(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "conf.d"), 0755)
+	files := map[string]string{
+		"main.ini":          "include = common.ini\n[main]\na=1\n!includedir conf.d\n[last]\nz=1\n",
+		"common.ini":        "[common]\nb=2\n",
+		"conf.d/20-b.ini":   "[dropin]\nd=20\n",
+		"conf.d/10-a.conf":  "[dropin]\nc=10\n",
+		"conf.d/ignored.md": "not ini\n",
+	}
+	for name, content := range files {
+		ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+
+	options := DefaultParseOptions()
+	options.Includes = true
+	myIni := new(Ini)
+	if err := myIni.LoadFromFileWithOptions(filepath.Join(dir, "main.ini"), options); err != nil {
+		t.Fatal("For", "LoadFromFileWithOptions(main.ini)", "expected", nil, "got", err)
+	}
+	s := fmt.Sprintf("%v %v", myIni.GetSections(), myIni.GetItems("dropin"))
+	if expected := "[common main dropin last] [c d]"; s != expected {
+		t.Error("For", "LoadFromFileWithOptions(main.ini)", "expected", expected, "got", s)
+	}
+	if myIni.Exists("", "include") {
+		t.Error("For", "Exists(,include)", "expected", false, "got", true)
+	}
+
+	// include cycle ///////////////////////////////////////////////////////
+	ioutil.WriteFile(filepath.Join(dir, "cycle.ini"), []byte("[a]\n!include cycle2.ini\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "cycle2.ini"), []byte("!include cycle.ini\n"), 0644)
+	err := myIni.LoadFromFileWithOptions(filepath.Join(dir, "cycle.ini"), options)
+	if perr, ok := err.(*ParseError); !ok || !strings.HasPrefix(perr.Reason, "include cycle") || perr.Line != 1 {
+		t.Error("For", "LoadFromFile(cycle.ini)", "expected", "an include cycle error", "got", err)
+	}
+
+	// missing file ///////////////////////////////////////////////////////
+	content := "!include does-not-exist.ini\n"
+	if err := myIni.LoadFromStringWithOptions(&content, options); err == nil {
+		t.Error("For", "LoadFromString(!include does-not-exist.ini)", "expected", "an error", "got", err)
+	}
+
+	// an include item in a section ///////////////////////////////////////////////////////
+	content = "[nginx]\ninclude = mime.types\n"
+	if err := myIni.LoadFromStringWithOptions(&content, options); err != nil {
+		t.Error("For", "LoadFromStringWithOptions([nginx] include)", "expected", nil, "got", err)
+	}
+	if s, _ := myIni.Get("nginx", "include"); s != "mime.types" {
+		t.Error("For", "Get(nginx,include)", "expected", "mime.types", "got", s)
+	}
+
+	// Includes disabled by default ///////////////////////////////////////////////////////
+	content = "include = value\n[a]\n!include does-not-exist.ini\nb=1\n"
+	if err := myIni.LoadFromString(&content); err != nil {
+		t.Error("For", "LoadFromString(!include)", "expected", nil, "got", err)
+	}
+	if s, _ := myIni.Get("", "include"); s != "value" {
+		t.Error("For", "Get(,include)", "expected", "value", "got", s)
+	}
+	myIni.Lossless = true
+	if s = myIni.Sprint(); s != content {
+		t.Error("For", "Sprint(!include)", "expected", content, "got", s)
+	}
+
+	// LoadFS with glob ///////////////////////////////////////////////////////
+	fsys := fstest.MapFS{
+		"etc/app.ini":       &fstest.MapFile{Data: []byte("[app]\n!include parts/*.ini\n")},
+		"etc/parts/b.ini":   &fstest.MapFile{Data: []byte("b=2\n")},
+		"etc/parts/a.ini":   &fstest.MapFile{Data: []byte("a=1\n")},
+		"etc/parts/c.other": &fstest.MapFile{Data: []byte("c=3\n")},
+	}
+	if err := myIni.LoadFSWithOptions(fsys, "etc/app.ini", options); err != nil {
+		t.Fatal("For", "LoadFSWithOptions(etc/app.ini)", "expected", nil, "got", err)
+	}
+	if s = fmt.Sprintf("%v", myIni.GetItems("app")); s != "[a b]" {
+		t.Error("For", "LoadFSWithOptions(etc/app.ini)", "expected", "[a b]", "got", s)
+	}
+}
@@ -0,0 +1,135 @@
+package ini
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultInterpolationDepth is the maximum depth of nested references when MaxInterpolationDepth is not set
+const defaultInterpolationDepth = 10
+
+// InterpolationError describes a reference which cannot be resolved by GetExpanded
+type InterpolationError struct {
+	// Item being expanded when the error occurred
+	Section string
+	Item    string
+
+	// Why the reference cannot be resolved
+	Reason string
+}
+
+// Error returns the error as "ini: cannot expand section:item: reason"
+func (e *InterpolationError) Error() string {
+	return fmt.Sprintf("ini: cannot expand %s:%s: %s", e.Section, e.Item, e.Reason)
+}
+
+/*
+GetExpanded returns the value of an item with its references replaced by the values they point to
+
+References are ${section:item}, ${item} or %(item)s for an item of the same section, they are
+expanded recursively. Use $$ and %% for literal $ and %. Returns an *InterpolationError if a
+referenced item does not exists, on a reference cycle or if MaxInterpolationDepth is exceeded
+
+Example :
+
+	// [paths]
+	// root=/opt/app
+	// logs=${root}/logs
+	// [server]
+	// access_log=${paths:logs}/access.log
+
+	accessLog, err := myIni.GetExpanded("server","access_log") // /opt/app/logs/access.log
+*/
+func (ini *Ini) GetExpanded(section string, item string) (string, error) {
+	if _, overridden := ini.envOverride(section, item); !overridden && !ini.ItemExists(section, item) { // like Get
+		return "", fmt.Errorf("%s.%s: %w", section, item, ErrNotExist)
+	}
+	return ini.expandItem(section, item, nil)
+}
+
+// expandItem expands the value of an item, chain holds the items being expanded as "section:item"
+func (ini *Ini) expandItem(section string, item string, chain []string) (string, error) {
+	ref := section + ":" + item
+	for i, previous := range chain {
+		if previous == ref {
+			return "", ini.interpolationError(chain, "reference cycle "+strings.Join(append(chain[i:], ref), " -> "))
+		}
+	}
+
+	depth := ini.MaxInterpolationDepth
+	if depth <= 0 {
+		depth = defaultInterpolationDepth
+	}
+	if len(chain) > depth {
+		return "", ini.interpolationError(chain, fmt.Sprintf("more than %d nested references", depth))
+	}
+
+	value, exists := ini.envOverride(section, item)
+	if !exists {
+		if !ini.ItemExists(section, item) {
+			return "", ini.interpolationError(chain, "missing reference "+ref)
+		}
+		value = ini.data[section].items[item].value() // environment variables are expanded below, in the same pass
+	}
+	chain = append(chain, ref)
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		rest := value[i:]
+		switch {
+		case strings.HasPrefix(rest, "$$"), strings.HasPrefix(rest, "%%"): // escaped
+			b.WriteByte(value[i])
+			i++
+
+		case ini.ExpandEnv && strings.HasPrefix(rest, "$") && !strings.HasPrefix(rest, "${"): // $VAR
+			text, length, _ := ini.envReference(rest)
+			b.WriteString(text)
+			i += length - 1
+
+		case strings.HasPrefix(rest, "${"):
+			if ini.ExpandEnv { // an environment variable, else a reference
+				if text, length, expanded := ini.envReference(rest); expanded {
+					b.WriteString(text)
+					i += length - 1
+					continue
+				}
+			}
+			end := strings.IndexByte(rest, '}')
+			if end < 0 {
+				return "", ini.interpolationError(chain, "unterminated reference "+rest)
+			}
+			refSection, refItem := section, rest[2:end]
+			if colon := strings.LastIndexByte(refItem, ':'); colon >= 0 {
+				refSection, refItem = refItem[:colon], refItem[colon+1:]
+			}
+			expanded, err := ini.expandItem(refSection, refItem, chain)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(expanded)
+			i += end
+
+		case strings.HasPrefix(rest, "%("):
+			end := strings.Index(rest, ")s")
+			if end < 0 {
+				return "", ini.interpolationError(chain, "unterminated reference "+rest)
+			}
+			expanded, err := ini.expandItem(section, rest[2:end], chain)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(expanded)
+			i += end + 1
+
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// interpolationError returns an error for the item which started the expansion
+func (ini *Ini) interpolationError(chain []string, reason string) *InterpolationError {
+	parts := strings.SplitN(chain[0], ":", 2)
+	return &InterpolationError{Section: parts[0], Item: parts[1], Reason: reason}
+}
@@ -0,0 +1,58 @@
+package ini
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetExpanded(t *testing.T) {
+	myIni := new(Ini)
+	content := `
+[paths]
+root=/opt/app
+logs=${root}/logs
+old=%(root)s/old
+price=$$5 100%%
+[server]
+access_log=${paths:logs}/access.log
+cycle1=${cycle2}
+cycle2=${cycle1}
+missing=${paths:nothing}
+open=${root
+`
+	myIni.LoadFromString(&content)
+
+	tests := map[[2]string]string{
+		{"paths", "logs"}:        "/opt/app/logs",
+		{"paths", "old"}:         "/opt/app/old",
+		{"paths", "price"}:       "$5 100%",
+		{"server", "access_log"}: "/opt/app/logs/access.log",
+	}
+	for key, expected := range tests {
+		if s, err := myIni.GetExpanded(key[0], key[1]); s != expected || err != nil {
+			t.Error("For", "GetExpanded", key, "expected", expected, "got", s, err)
+		}
+	}
+
+	// errors ///////////////////////////////////////////////////////
+	_, err := myIni.GetExpanded("server", "cycle1")
+	if expected := "ini: cannot expand server:cycle1: reference cycle server:cycle1 -> server:cycle2 -> server:cycle1"; err == nil || err.Error() != expected {
+		t.Error("For", "GetExpanded(server,cycle1)", "expected", expected, "got", err)
+	}
+	_, err = myIni.GetExpanded("server", "missing")
+	if expected := "ini: cannot expand server:missing: missing reference paths:nothing"; err == nil || err.Error() != expected {
+		t.Error("For", "GetExpanded(server,missing)", "expected", expected, "got", err)
+	}
+	if _, err = myIni.GetExpanded("server", "open"); err == nil {
+		t.Error("For", "GetExpanded(server,open)", "expected", "an error", "got", err)
+	}
+	if _, err = myIni.GetExpanded("server", "does not exists"); !errors.Is(err, ErrNotExist) {
+		t.Error("For", "GetExpanded(server,does not exists)", "expected", ErrNotExist, "got", err)
+	}
+
+	// depth ///////////////////////////////////////////////////////
+	myIni.MaxInterpolationDepth = 1
+	if _, err = myIni.GetExpanded("server", "access_log"); err == nil {
+		t.Error("For", "GetExpanded(server,access_log) with depth 1", "expected", "an error", "got", err)
+	}
+}
@@ -0,0 +1,144 @@
+package ini
+
+import "os"
+
+/*
+Layers stacks several Ini, like built-in defaults, a system file, a user file and a local file.
+Get consults the layers from the last added (highest precedence) to the first one
+
+Example :
+
+	layers := ini.NewLayers(defaults)
+	layers.AddFile("/etc/app.ini", true)
+	layers.AddFile(filepath.Join(home, ".app.ini"), true)
+	layers.AddFile("app.ini", true)
+
+	host, _ := layers.Get("Server","host")
+	source, _ := layers.Source("Server","host")
+	print("host comes from ", source.Filename, ":", source.Line, "\n")
+*/
+type Layers struct {
+	layers []*Ini
+}
+
+// Source tells where the value returned by Layers.Get comes from
+type Source struct {
+	// Name of the file of the value, empty if loaded from a string or set by the program
+	Filename string
+
+	// Line of the value in the file, 0 if set by the program
+	Line int
+
+	// Index of the layer, 0 is the first added (lowest precedence)
+	Layer int
+}
+
+// NewLayers returns the layers made of inis, from the lowest precedence to the highest
+func NewLayers(inis ...*Ini) *Layers {
+	layers := new(Layers)
+	for _, ini := range inis {
+		layers.Add(ini)
+	}
+	return layers
+}
+
+// Add adds a layer with a higher precedence than all the others
+func (l *Layers) Add(ini *Ini) {
+	l.layers = append(l.layers, ini)
+}
+
+/*
+AddFile loads a file and adds it as a layer with a higher precedence than all the others
+
+If optional is true, a file which does not exists is skipped without error
+*/
+func (l *Layers) AddFile(filename string, optional bool) error {
+	ini := new(Ini)
+	if err := ini.LoadFromFile(filename); err != nil {
+		if optional && os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	l.Add(ini)
+	return nil
+}
+
+// Layers returns the layers, from the lowest precedence to the highest
+func (l *Layers) Layers() []*Ini {
+	layers := make([]*Ini, len(l.layers))
+	copy(layers, l.layers)
+	return layers
+}
+
+// find returns the index of the layer with the highest precedence defining the item, -1 if none
+func (l *Layers) find(section string, item string) int {
+	for i := len(l.layers) - 1; i >= 0; i-- {
+		if l.layers[i].ItemExists(section, item) {
+			return i
+		}
+	}
+	return -1
+}
+
+/*
+Get returns the value of an item from the layer with the highest precedence defining it (and true as second return value)
+
+If no layer defines the item, return false as second return value
+*/
+func (l *Layers) Get(section string, item string) (string, bool) {
+	if i := l.find(section, item); i >= 0 {
+		return l.layers[i].GetItem(section, item)
+	}
+	return "", false
+}
+
+// Exists returns true if at least one layer defines the item
+func (l *Layers) Exists(section string, item string) bool {
+	return l.find(section, item) >= 0
+}
+
+// GetSections returns the sections of all the layers, in the order they first appear
+func (l *Layers) GetSections() []string {
+	sections := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, ini := range l.layers {
+		for _, section := range ini.GetSections() {
+			if !seen[section] {
+				seen[section] = true
+				sections = append(sections, section)
+			}
+		}
+	}
+	return sections
+}
+
+// GetItems returns the items of a section in all the layers, in the order they first appear
+func (l *Layers) GetItems(section string) []string {
+	items := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, ini := range l.layers {
+		for _, item := range ini.GetItems(section) {
+			if !seen[item] {
+				seen[item] = true
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+/*
+Source returns where the value returned by Get comes from (and true as second return value)
+
+If no layer defines the item, return false as second return value
+*/
+func (l *Layers) Source(section string, item string) (Source, bool) {
+	i := l.find(section, item)
+	if i < 0 {
+		return Source{}, false
+	}
+	values := l.layers[i].data[section].items[item].values
+	pos := values[len(values)-1].position
+	return Source{Filename: pos.filename, Line: pos.line, Layer: i}, true
+}
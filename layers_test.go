@@ -0,0 +1,62 @@
+package ini
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestLayers(t *testing.T) {
+	dir := t.TempDir()
+	system := filepath.Join(dir, "system.ini")
+	ioutil.WriteFile(system, []byte("[Server]\nhost=system\n\nport=8080\n"), 0644)
+	local := filepath.Join(dir, "local.ini")
+	ioutil.WriteFile(local, []byte("[Server]\nport=9090\n[Local]\nonly=here\n"), 0644)
+
+	defaults := new(Ini)
+	defaults.SetOrCreate("Server", "host", "localhost")
+	defaults.SetOrCreate("Server", "debug", "false")
+
+	layers := NewLayers(defaults)
+	if err := layers.AddFile(system, false); err != nil {
+		t.Fatal("For", "AddFile(system)", "expected", nil, "got", err)
+	}
+	if err := layers.AddFile(filepath.Join(dir, "missing.ini"), true); err != nil {
+		t.Error("For", "AddFile(missing, optional)", "expected", nil, "got", err)
+	}
+	if err := layers.AddFile(filepath.Join(dir, "missing.ini"), false); err == nil {
+		t.Error("For", "AddFile(missing)", "expected", "an error", "got", err)
+	}
+	layers.AddFile(local, false)
+
+	// Get ///////////////////////////////////////////////////////
+	tests := map[string]string{"host": "system", "port": "9090", "debug": "false"}
+	for item, expected := range tests {
+		if s, _ := layers.Get("Server", item); s != expected {
+			t.Error("For", "Get(Server,"+item+")", "expected", expected, "got", s)
+		}
+	}
+	if _, exists := layers.Get("Server", "does not exists"); exists {
+		t.Error("For", "Get(Server,does not exists)", "expected", false, "got", exists)
+	}
+
+	// GetSections, GetItems ///////////////////////////////////////////////////////
+	s := fmt.Sprintf("%v %v", layers.GetSections(), layers.GetItems("Server"))
+	if expected := "[Server Local] [host debug port]"; s != expected {
+		t.Error("For", "GetSections(), GetItems(Server)", "expected", expected, "got", s)
+	}
+
+	// Source ///////////////////////////////////////////////////////
+	tests = map[string]string{
+		"host":  fmt.Sprintf("{%s 2 1}", system),
+		"port":  fmt.Sprintf("{%s 2 2}", local),
+		"debug": "{ 0 0}",
+	}
+	for item, expected := range tests {
+		source, _ := layers.Source("Server", item)
+		if s = fmt.Sprintf("%v", source); s != expected {
+			t.Error("For", "Source(Server,"+item+")", "expected", expected, "got", s)
+		}
+	}
+}
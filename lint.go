@@ -0,0 +1,214 @@
+package ini
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Severity tells how serious a Finding is
+type Severity int
+
+const (
+	// SeverityInfo is a style problem
+	SeverityInfo Severity = iota
+
+	// SeverityWarning is a construct the parser accepts but which is likely a mistake
+	SeverityWarning
+
+	// SeverityError is a construct the parser cannot read
+	SeverityError
+)
+
+// String returns the name of the severity : "info", "warning" or "error"
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// MarshalText returns the name of the severity, for the JSON output
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Lint rules
+const (
+	RuleDuplicateKey        = "duplicate-key"         // item defined twice in a section, without brackets
+	RuleDuplicateSection    = "duplicate-section"     // section header found twice
+	RuleKeyOutsideSection   = "key-outside-section"   // item before the first section header
+	RuleEmptySection        = "empty-section"         // section without items
+	RuleTrailingWhitespace  = "trailing-whitespace"   // spaces or tabulations at the end of a line
+	RuleMixedCommentMarkers = "mixed-comment-markers" // comment using another marker than the first comment
+	RuleMixedLineEndings    = "mixed-line-endings"    // line ending other than the one of the first line
+	RuleUnparseableLine     = "unparseable-line"      // neither blank, a comment, a section header nor an item
+	RuleCaseDuplicateKey    = "case-duplicate-key"    // items of a section differing only by case
+)
+
+// Finding is a problem reported by Lint
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Filename string   `json:"filename,omitempty"`
+	Line     int      `json:"line"`
+	Message  string   `json:"message"`
+}
+
+// String returns the finding as "filename:line: severity: message [rule]"
+func (f Finding) String() string {
+	position := strconv.Itoa(f.Line)
+	if f.Filename != "" {
+		position = f.Filename + ":" + position
+	}
+	return position + ": " + f.Severity.String() + ": " + f.Message + " [" + f.Rule + "]"
+}
+
+/*
+Lint returns the problems of the content read by the last LoadFromFile, LoadFromString, Load or LoadFS, see
+LintString. The changes made since the load (Set, AddItem, LoadJSON...) are not checked, lint the output of
+Sprint to check them
+
+Example :
+
+	myIni.LoadFromFileWithOptions("config.ini", ini.ParseOptions{})
+	for _, finding := range ini.Lint(myIni) {
+		print(finding.String(), "\n")
+	}
+*/
+func Lint(ini *Ini) []Finding {
+	return LintString(ini.source, ini.Filename)
+}
+
+/*
+LintString returns the problems of the ini content, sorted by line. filename is only used in the findings,
+the included files are not checked. The parser accepts most of these problems, the unparseable lines are
+reported too
+
+Example :
+
+	content, err := ioutil.ReadFile("config.ini")
+	...
+	for _, finding := range ini.LintString(string(content), "config.ini") {
+		print(finding.String(), "\n")
+	}
+*/
+func LintString(content string, filename string) []Finding {
+	findings := make([]Finding, 0)
+	add := func(rule string, severity Severity, line int, format string, args ...interface{}) {
+		findings = append(findings, Finding{Rule: rule, Severity: severity, Filename: filename, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	type sectionState struct {
+		line  int            // first header
+		items int            // number of items in all the parts
+		names map[string]int // line of each item
+		lower map[string]string
+	}
+	sections := make(map[string]*sectionState)
+	sectionOrder := make([]string, 0)
+	current := ""
+	state := func(section string, line int) *sectionState {
+		s, exists := sections[section]
+		if !exists {
+			s = &sectionState{line: line, names: make(map[string]int), lower: make(map[string]string)}
+			sections[section] = s
+			sectionOrder = append(sectionOrder, section)
+		}
+		return s
+	}
+	bracketed := make(map[string]bool) // section and item written name[]=value
+
+	lineEnding, commentMarker := "", byte(0)
+	for i, raw := range strings.SplitAfter(content, "\n") {
+		line := i + 1
+		if raw == "" { // nothing after the last line ending
+			continue
+		}
+		value := strings.TrimRight(raw, "\r\n")
+
+		if ending := raw[len(value):]; ending != "" {
+			if lineEnding == "" {
+				lineEnding = ending
+			} else if ending != lineEnding {
+				add(RuleMixedLineEndings, SeverityWarning, line, "line ending %q differs from %q of the first line", ending, lineEnding)
+			}
+		}
+		if strings.TrimRight(value, " \t") != value {
+			add(RuleTrailingWhitespace, SeverityInfo, line, "trailing whitespace")
+		}
+
+		if reInclude.MatchString(value) { // loaded or kept as is
+			continue
+		}
+		if reComment.MatchString(value) {
+			marker := strings.TrimSpace(value)[0]
+			if commentMarker == 0 {
+				commentMarker = marker
+			} else if marker != commentMarker {
+				add(RuleMixedCommentMarkers, SeverityInfo, line, "comment marker %q differs from %q of the first comment", marker, commentMarker)
+			}
+			continue
+		}
+
+		if matches := reSection.FindStringSubmatch(value); matches != nil {
+			current = strings.TrimSpace(matches[1])
+			if s, exists := sections[current]; exists && current != "" {
+				add(RuleDuplicateSection, SeverityWarning, line, "section %q already defined on line %d", current, s.line)
+			}
+			state(current, line)
+			continue
+		}
+
+		if matches := reItem.FindStringSubmatch(value); matches != nil {
+			name := strings.TrimSpace(matches[1])
+			brackets := strings.HasSuffix(name, "[]")
+			if brackets {
+				name = strings.TrimSpace(strings.TrimSuffix(name, "[]"))
+			}
+
+			if current == "" {
+				add(RuleKeyOutsideSection, SeverityWarning, line, "item %q outside any section", name)
+			}
+			s := state(current, line)
+			s.items++
+
+			key := current + "\x00" + name
+			if first, exists := s.names[name]; exists {
+				if !(brackets && bracketed[key]) {
+					add(RuleDuplicateKey, SeverityWarning, line, "item %q already defined on line %d", name, first)
+				}
+			} else {
+				s.names[name] = line
+				bracketed[key] = brackets
+				if other, exists := s.lower[strings.ToLower(name)]; exists {
+					add(RuleCaseDuplicateKey, SeverityWarning, line, "item %q differs only by case from %q on line %d", name, other, s.names[other])
+				} else {
+					s.lower[strings.ToLower(name)] = name
+				}
+			}
+			continue
+		}
+
+		if strings.TrimSpace(value) != "" {
+			add(RuleUnparseableLine, SeverityError, line, "%s", newParseError(filename, line, value).Reason)
+		}
+	}
+
+	for _, section := range sectionOrder {
+		if s := sections[section]; s.items == 0 {
+			add(RuleEmptySection, SeverityInfo, s.line, "section %q has no items", section)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Line < findings[j].Line
+	})
+	return findings
+}
@@ -0,0 +1,66 @@
+package ini
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	content := "top=1\n" + // 1
+		"; comment\n" + // 2
+		"[Server]\n" + // 3
+		"host=a \n" + // 4
+		"host=b\r\n" + // 5
+		"Host=c\n" + // 6
+		"# other comment\n" + // 7
+		"alias[]=x\n" + // 8
+		"alias[]=y\n" + // 9
+		"not an item\n" + // 10
+		"[Empty]\n" + // 11
+		"[Server]\n" + // 12
+		"port=80\n" // 13
+
+	expected := []struct {
+		line     int
+		rule     string
+		severity Severity
+	}{
+		{1, RuleKeyOutsideSection, SeverityWarning},
+		{4, RuleTrailingWhitespace, SeverityInfo},
+		{5, RuleMixedLineEndings, SeverityWarning},
+		{5, RuleDuplicateKey, SeverityWarning},
+		{6, RuleCaseDuplicateKey, SeverityWarning},
+		{7, RuleMixedCommentMarkers, SeverityInfo},
+		{10, RuleUnparseableLine, SeverityError},
+		{11, RuleEmptySection, SeverityInfo},
+		{12, RuleDuplicateSection, SeverityWarning},
+	}
+	findings := LintString(content, "config.ini")
+	if len(findings) != len(expected) {
+		t.Fatal("For", "LintString", "expected", len(expected), "findings", "got", findings)
+	}
+	for i, e := range expected {
+		f := findings[i]
+		if f.Line != e.line || f.Rule != e.rule || f.Severity != e.severity || f.Filename != "config.ini" {
+			t.Error("For", "LintString", i, "expected", e, "got", f.String())
+		}
+	}
+
+	// Lint checks the loaded content, not the changes made since ///////////////////////////////////////////////////////
+	myIni := new(Ini)
+	myIni.Filename = "config.ini"
+	myIni.LoadFromStringWithOptions(&content, ParseOptions{})
+	if s := fmt.Sprintf("%v", Lint(myIni)); s != fmt.Sprintf("%v", findings) {
+		t.Error("For", "Lint", "expected", findings, "got", s)
+	}
+	myIni.Set("Server", "host", "fixed")
+	if s := fmt.Sprintf("%v", Lint(myIni)); s != fmt.Sprintf("%v", findings) {
+		t.Error("For", "Lint(after Set)", "expected", findings, "got", s)
+	}
+
+	// clean content ///////////////////////////////////////////////////////
+	content = "; comment\n[Server]\nhost=a\n!include extra.ini\n"
+	if findings := LintString(content, ""); len(findings) != 0 {
+		t.Error("For", "LintString", "expected", 0, "got", findings)
+	}
+}
@@ -0,0 +1,195 @@
+package ini
+
+// Conflict is an item changed differently by both sides of a Merge
+type Conflict struct {
+	Section string
+	Item    string
+
+	// Values of the item in each document, nil when the item does not exists
+	Base   []string
+	Ours   []string
+	Theirs []string
+}
+
+/*
+Merge applies to a copy of ours the changes from base to theirs, and returns it with the conflicts
+
+An item changed by one side only takes the values of that side, an item changed by both sides to different values
+is a conflict and keeps the values of ours. An item changed or added by theirs in a section removed by ours is a
+conflict too, the section stays removed. Comments are taken from the side which changed them, or kept from ours
+if both did. Sections and items added by theirs are placed after their predecessor, and a section removed by theirs
+is removed unless ours still holds items in it. The formatting of ours is kept, the lines taken from theirs keep
+their own formatting
+
+Example :
+
+	base, ours, theirs := new(ini.Ini), new(ini.Ini), new(ini.Ini)
+	base.LoadFromFile("app.ini.orig")  // the previous packaged default
+	ours.LoadFromFile("app.ini")       // edited by the user
+	theirs.LoadFromFile("app.ini.new") // the new packaged default
+
+	merged, conflicts := ini.Merge(base, ours, theirs)
+	for _, conflict := range conflicts {
+		print("conflict on ", conflict.Section, " ", conflict.Item, "\n")
+	}
+	merged.Save("app.ini")
+*/
+func Merge(base *Ini, ours *Ini, theirs *Ini) (*Ini, []Conflict) {
+	merged := ours.clone()
+	theirs = theirs.clone() // the sections and items taken from theirs must not be shared
+	conflicts := make([]Conflict, 0)
+
+	sections := ours.GetSections()
+	for _, section := range theirs.GetSections() {
+		if !ours.SectionExists(section) {
+			sections = append(sections, section)
+		}
+	}
+
+	for _, section := range sections {
+		if !merged.SectionExists(section) {
+			if base.SectionExists(section) { // removed by ours, a conflict for each item changed or added by theirs
+				for _, item := range theirs.GetItems(section) {
+					baseValues, theirValues := mergeValues(base, section, item), mergeValues(theirs, section, item)
+					if !sameMergeValues(baseValues, theirValues) {
+						conflicts = append(conflicts, Conflict{Section: section, Item: item, Base: baseValues, Theirs: theirValues})
+					}
+				}
+				continue
+			}
+			merged.insertSection(section, theirs)
+			continue
+		}
+
+		if theirs.SectionExists(section) {
+			baseComments, ourComments, theirComments := base.GetSectionComments(section), ours.GetSectionComments(section), theirs.GetSectionComments(section)
+			if sameValues(baseComments, ourComments) && !sameValues(baseComments, theirComments) {
+				s := merged.data[section]
+				s.comments = copyStrings(theirComments)
+				s.lead = nil // comments must be rendered again
+				merged.data[section] = s
+			}
+		}
+
+		items := ours.GetItems(section)
+		for _, item := range theirs.GetItems(section) {
+			if !ours.ItemExists(section, item) {
+				items = append(items, item)
+			}
+		}
+		for _, item := range items {
+			if conflict, ok := merged.mergeItem(section, item, base, ours, theirs); !ok {
+				conflicts = append(conflicts, conflict)
+			}
+		}
+
+		if base.SectionExists(section) && !theirs.SectionExists(section) && len(merged.GetItems(section)) == 0 {
+			merged.DeleteSection(section)
+		}
+	}
+	return merged, conflicts
+}
+
+// mergeItem applies to ini the changes of an item from base to theirs, returns false with the conflict if ours changed it too
+func (ini *Ini) mergeItem(section string, item string, base *Ini, ours *Ini, theirs *Ini) (Conflict, bool) {
+	baseValues, ourValues, theirValues := mergeValues(base, section, item), mergeValues(ours, section, item), mergeValues(theirs, section, item)
+	oursChanged, theirsChanged := !sameMergeValues(baseValues, ourValues), !sameMergeValues(baseValues, theirValues)
+
+	switch {
+	case theirsChanged && oursChanged && !sameMergeValues(ourValues, theirValues):
+		return Conflict{Section: section, Item: item, Base: baseValues, Ours: ourValues, Theirs: theirValues}, false
+	case theirsChanged && !oursChanged && theirValues == nil:
+		ini.DeleteItem(section, item)
+		return Conflict{}, true
+	case theirsChanged && !oursChanged && ourValues == nil:
+		ini.insertItem(section, item, theirs)
+		return Conflict{}, true
+	case theirsChanged && !oursChanged:
+		ini.SetAll(section, item, theirValues)
+	}
+
+	if ini.ItemExists(section, item) && theirs.ItemExists(section, item) {
+		baseComments, ourComments, theirComments := base.GetItemComments(section, item), ours.GetItemComments(section, item), theirs.GetItemComments(section, item)
+		if sameValues(baseComments, ourComments) && !sameValues(baseComments, theirComments) {
+			i := ini.data[section].items[item]
+			i.comments = copyStrings(theirComments)
+			i.clearLeads() // comments must be rendered again
+			ini.data[section].items[item] = i
+		}
+	}
+	return Conflict{}, true
+}
+
+// insertSection copies a section of from into ini, after the section preceding it in from
+func (ini *Ini) insertSection(section string, from *Ini) {
+	if ini.data == nil {
+		ini.data = make(map[string]Section)
+	}
+	ini.data[section] = from.data[section]
+	ini.order = insertAfter(ini.order, section, from.order, ini.SectionExists)
+}
+
+// insertItem copies an item of from into ini, after the item preceding it in from
+func (ini *Ini) insertItem(section string, item string, from *Ini) {
+	s := ini.data[section]
+	if s.items == nil {
+		s.items = make(map[string]Item)
+	}
+	s.items[item] = from.data[section].items[item]
+	s.order = insertAfter(s.order, item, from.data[section].order, func(name string) bool {
+		_, ok := s.items[name]
+		return ok
+	})
+	ini.data[section] = s
+}
+
+// insertAfter inserts name into order after the last name preceding it in reference for which exists is true, first if none
+func insertAfter(order []string, name string, reference []string, exists func(name string) bool) []string {
+	after, found := "", false
+	for _, previous := range reference {
+		if previous == name {
+			break
+		}
+		if exists(previous) && containsName(order, previous) {
+			after, found = previous, true
+		}
+	}
+
+	result := make([]string, 0, len(order)+1)
+	if !found {
+		result = append(result, name)
+	}
+	for _, n := range order {
+		if n == name {
+			continue
+		}
+		result = append(result, n)
+		if found && n == after {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// containsName returns true if name is in names
+func containsName(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeValues returns the raw values of an item, nil if it does not exists
+func mergeValues(ini *Ini, section string, item string) []string {
+	if !ini.ItemExists(section, item) {
+		return nil
+	}
+	return ini.rawValues(section, item)
+}
+
+// sameMergeValues returns true if a and b are both missing or hold the same values
+func sameMergeValues(a []string, b []string) bool {
+	return (a == nil) == (b == nil) && sameValues(a, b)
+}
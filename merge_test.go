@@ -0,0 +1,84 @@
+package ini
+
+import (
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	baseContent := "[Server]\nhost=localhost\nport=80\n; seconds\ntimeout=30\nworkers=4\n[Log]\nlevel=info\n[Legacy]\nold=1\n"
+	oursContent := "[Server]\nhost=example.com\nport=80\n; seconds\ntimeout=30\nworkers=8\n[Log]\nlevel=info\n[Legacy]\nold=1\n"
+	theirsContent := "[Server]\nhost=localhost\nport=8080\n; timeout in seconds\ntimeout=30\nworkers=16\n[Log]\nlevel=info\nfile=app.log\n[Cache]\nsize=10\n"
+	base, ours, theirs := new(Ini), new(Ini), new(Ini)
+	base.LoadFromString(&baseContent)
+	ours.LoadFromString(&oursContent)
+	theirs.LoadFromString(&theirsContent)
+
+	merged, conflicts := Merge(base, ours, theirs)
+
+	tests := map[string]string{
+		"host":    "example.com", // changed by ours
+		"port":    "8080",        // changed by theirs
+		"timeout": "30",
+		"workers": "8", // conflict, ours is kept
+	}
+	for item, expected := range tests {
+		if s, _ := merged.Get("Server", item); s != expected {
+			t.Error("For", "Get(Server,"+item+")", "expected", expected, "got", s)
+		}
+	}
+	if s, _ := merged.Get("Log", "file"); s != "app.log" {
+		t.Error("For", "Get(Log,file)", "expected", "app.log", "got", s)
+	}
+	if merged.SectionExists("Legacy") {
+		t.Error("For", "SectionExists(Legacy)", "expected", false, "got", true)
+	}
+	if !sameValues(merged.GetSections(), []string{"Server", "Log", "Cache"}) {
+		t.Error("For", "GetSections", "expected", []string{"Server", "Log", "Cache"}, "got", merged.GetSections())
+	}
+	if c := merged.GetItemComments("Server", "timeout"); !sameValues(c, []string{"timeout in seconds"}) {
+		t.Error("For", "GetItemComments(Server,timeout)", "expected", "timeout in seconds", "got", c)
+	}
+
+	if len(conflicts) != 1 {
+		t.Fatal("For", "Merge", "expected", 1, "conflict", "got", conflicts)
+	}
+	c := conflicts[0]
+	if c.Section != "Server" || c.Item != "workers" || !sameValues(c.Base, []string{"4"}) || !sameValues(c.Ours, []string{"8"}) || !sameValues(c.Theirs, []string{"16"}) {
+		t.Error("For", "Merge", "expected", "conflict on Server workers", "got", c)
+	}
+
+	// the inputs are not modified ///////////////////////////////////////////////////////
+	if s, _ := ours.Get("Server", "port"); s != "80" {
+		t.Error("For", "ours.Get(Server,port)", "expected", "80", "got", s)
+	}
+
+	// items added by theirs follow their predecessor ///////////////////////////////////////////////////////
+	theirsContent = "[Server]\nhost=localhost\nbind=0.0.0.0\nport=80\n; seconds\ntimeout=30\nworkers=4\n[Log]\nlevel=info\n[Legacy]\nold=1\n"
+	theirs.LoadFromString(&theirsContent)
+	merged, _ = Merge(base, ours, theirs)
+	expected := []string{"host", "bind", "port", "timeout", "workers"}
+	if items := merged.GetItems("Server"); !sameValues(items, expected) {
+		t.Error("For", "GetItems(Server)", "expected", expected, "got", items)
+	}
+
+	// section removed by ours and changed by theirs ///////////////////////////////////////////////////////
+	oursContent = "[Server]\nhost=localhost\n"
+	baseContent = "[Server]\nhost=localhost\n[Legacy]\nold=1\nkept=1\n"
+	theirsContent = "[Server]\nhost=localhost\n[Legacy]\nold=2\nkept=1\nnew=1\n"
+	ours.LoadFromString(&oursContent)
+	base.LoadFromString(&baseContent)
+	theirs.LoadFromString(&theirsContent)
+	merged, conflicts = Merge(base, ours, theirs)
+	if merged.SectionExists("Legacy") {
+		t.Error("For", "SectionExists(Legacy)", "expected", false, "got", true)
+	}
+	if len(conflicts) != 2 {
+		t.Fatal("For", "Merge(removed section)", "expected", 2, "conflicts", "got", conflicts)
+	}
+	if c = conflicts[0]; c.Section != "Legacy" || c.Item != "old" || c.Ours != nil || !sameValues(c.Theirs, []string{"2"}) {
+		t.Error("For", "Merge(removed section)", "expected", "conflict on Legacy old", "got", c)
+	}
+	if c = conflicts[1]; c.Item != "new" || c.Base != nil || !sameValues(c.Theirs, []string{"1"}) {
+		t.Error("For", "Merge(removed section)", "expected", "conflict on Legacy new", "got", c)
+	}
+}
@@ -0,0 +1,154 @@
+package ini
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// quoteString returns s as a double quoted string, valid for ini values, JSON, TOML, YAML and dotenv
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString("\\\"")
+		case '\\':
+			b.WriteString("\\\\")
+		case '\b':
+			b.WriteString("\\b")
+		case '\t':
+			b.WriteString("\\t")
+		case '\n':
+			b.WriteString("\\n")
+		case '\f':
+			b.WriteString("\\f")
+		case '\r':
+			b.WriteString("\\r")
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, "\\u%04X", r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+/*
+unquoteString reads the double quoted string at the start of s, returns it unescaped with the rest of s
+
+The escapes are \b \t \n \f \r \" \\ \/ \uXXXX and \UXXXXXXXX
+*/
+func unquoteString(s string) (string, string, error) {
+	if !strings.HasPrefix(s, "\"") {
+		return "", s, fmt.Errorf("missing opening quote")
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '"':
+			return b.String(), s[i+1:], nil
+		case c != '\\':
+			b.WriteByte(c)
+			i++
+		case i+1 >= len(s):
+			return "", s, fmt.Errorf("unterminated escape")
+		default:
+			escape := s[i+1]
+			i += 2
+			switch escape {
+			case 'b':
+				b.WriteByte('\b')
+			case 't':
+				b.WriteByte('\t')
+			case 'n':
+				b.WriteByte('\n')
+			case 'f':
+				b.WriteByte('\f')
+			case 'r':
+				b.WriteByte('\r')
+			case '"', '\\', '/':
+				b.WriteByte(escape)
+			case 'u', 'U':
+				size := 4
+				if escape == 'U' {
+					size = 8
+				}
+				if i+size > len(s) {
+					return "", s, fmt.Errorf("invalid escape \\%c", escape)
+				}
+				code, err := strconv.ParseUint(s[i:i+size], 16, 32)
+				if err != nil || !utf8.ValidRune(rune(code)) {
+					return "", s, fmt.Errorf("invalid escape \\%c%s", escape, s[i:i+size])
+				}
+				b.WriteRune(rune(code))
+				i += size
+			default:
+				return "", s, fmt.Errorf("invalid escape \\%c", escape)
+			}
+		}
+	}
+	return "", s, fmt.Errorf("missing closing quote")
+}
+
+// unquoteLiteral reads the single quoted string at the start of s, where two quotes stand for one if doubled is true
+func unquoteLiteral(s string, doubled bool) (string, string, error) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] != '\'' {
+			b.WriteByte(s[i])
+		} else if doubled && i+1 < len(s) && s[i+1] == '\'' {
+			b.WriteByte('\'')
+			i++
+		} else {
+			return b.String(), s[i+1:], nil
+		}
+	}
+	return "", s, fmt.Errorf("missing closing quote")
+}
+
+// needsQuotes returns true if a value would not be read back as it is without quotes : leading or trailing
+// spaces, control characters, a leading quote. A comment marker after "=" is part of the value
+func needsQuotes(value string) bool {
+	if value == "" {
+		return false
+	}
+	if value != strings.TrimSpace(value) || value[0] == '"' || value[0] == '\'' {
+		return true
+	}
+	return strings.IndexFunc(value, unicode.IsControl) >= 0
+}
+
+// quoteValue returns value as written in an item line, double quoted if needed
+func quoteValue(value string) string {
+	if needsQuotes(value) {
+		return quoteString(value)
+	}
+	return value
+}
+
+// unquoteValue returns the value of an item line : the content of a double quoted value with its escapes
+// replaced, the content of a single quoted value as it is, the value itself if not entirely quoted
+func unquoteValue(value string) string {
+	var unquoted, rest string
+	var err error
+	switch {
+	case strings.HasPrefix(value, "\""):
+		unquoted, rest, err = unquoteString(value)
+	case strings.HasPrefix(value, "'"):
+		unquoted, rest, err = unquoteLiteral(value, false)
+	default:
+		return value
+	}
+	if err != nil || strings.TrimSpace(rest) != "" {
+		return value
+	}
+	return unquoted
+}
@@ -0,0 +1,63 @@
+package ini
+
+import (
+	"testing"
+)
+
+func TestQuotedValues(t *testing.T) {
+	content := "[templates]\n" +
+		"padded = \"  padded  \"\n" +
+		"multiline = \"Hello,\\n\\tWorld \\\"quoted\\\" \\\\ \\u00e9\"\n" +
+		"literal = '  C:\\temp ; not a comment  '\n" +
+		"partial = \"a\" b\n" +
+		"invalid = \"\\q\"\n" +
+		"plain = a \"b\" c\n"
+	myIni := new(Ini)
+	if err := myIni.LoadFromString(&content); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"padded":    "  padded  ",
+		"multiline": "Hello,\n\tWorld \"quoted\" \\ é",
+		"literal":   "  C:\\temp ; not a comment  ",
+		"partial":   "\"a\" b", // not entirely quoted, kept as it is
+		"invalid":   "\"\\q\"", // unknown escape, kept as it is
+		"plain":     "a \"b\" c",
+	}
+	for item, expected := range tests {
+		if s, _ := myIni.Get("templates", item); s != expected {
+			t.Errorf("For Get(templates,%s) expected %q got %q", item, expected, s)
+		}
+	}
+
+	// values are quoted when needed and read back ///////////////////////////////////////////////////////
+	values := []string{"  lead", "trail ", "a;b", "#tag", "\"quoted", "'single", "line\nbreak", "tab\there", "plain value", "mid \"quote\"", ""}
+	myIni = new(Ini)
+	for _, value := range values {
+		myIni.SetOrCreate("s", "v", value)
+		s := myIni.Sprint()
+		reloaded := new(Ini)
+		if err := reloaded.LoadFromString(&s); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := reloaded.Get("s", "v"); got != value {
+			t.Errorf("For Sprint(%q) got %q back from %q", value, got, s)
+		}
+	}
+
+	myIni.Set("s", "v", "plain value")
+	if s, expected := myIni.Sprint(), "[s]\nv=plain value\n"; s != expected {
+		t.Errorf("For Sprint() expected %q got %q", expected, s)
+	}
+	for _, value := range []string{"#ff0000", "http://h/#a", "a ; b"} { // comment markers are not quoted
+		myIni.Set("s", "v", value)
+		if s, expected := myIni.Sprint(), "[s]\nv="+value+"\n"; s != expected {
+			t.Errorf("For Sprint() expected %q got %q", expected, s)
+		}
+	}
+	myIni.Set("s", "v", " x;\n")
+	if s, expected := myIni.Sprint(), "[s]\nv=\" x;\\n\"\n"; s != expected {
+		t.Errorf("For Sprint() expected %q got %q", expected, s)
+	}
+}
@@ -0,0 +1,404 @@
+package ini
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+/*
+SafeIni wraps an Ini with a read/write lock, so its methods can be called from several goroutines
+
+Read and Update give access to all the methods of the Ini, Snapshot returns an immutable copy which
+can be read without lock while the writers continue
+
+Example :
+
+	safe := ini.NewSafeIni(myIni)
+
+	go safe.Set("Server","host","127.0.0.1") // admin endpoint
+
+	host, _ := safe.Get("Server","host") // request handler
+
+	snapshot := safe.Snapshot() // consistent view of the whole configuration
+*/
+type SafeIni struct {
+	mu  sync.RWMutex
+	ini *Ini
+}
+
+// NewSafeIni returns a SafeIni wrapping ini, which must not be used directly anymore
+func NewSafeIni(ini *Ini) *SafeIni {
+	return &SafeIni{ini: ini}
+}
+
+/*
+Read calls fn holding the read lock, fn must not modify ini nor keep it
+
+Example :
+
+	safe.Read(func(myIni *ini.Ini) {
+		port = myIni.GetIntDefault("Server","port",80)
+		host, _ = myIni.Get("Server","host")
+	})
+*/
+func (s *SafeIni) Read(fn func(ini *Ini)) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	fn(s.ini)
+}
+
+// Update calls fn holding the write lock, fn must not keep ini
+func (s *SafeIni) Update(fn func(ini *Ini)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(s.ini)
+}
+
+// Snapshot returns an immutable deep copy of the current content
+func (s *SafeIni) Snapshot() *Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ini.Snapshot()
+}
+
+// Get is the locked version of Ini.Get
+func (s *SafeIni) Get(section string, item string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ini.Get(section, item)
+}
+
+// GetAll is the locked version of Ini.GetAll
+func (s *SafeIni) GetAll(section string, item string) ([]string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ini.GetAll(section, item)
+}
+
+// Exists is the locked version of Ini.Exists
+func (s *SafeIni) Exists(section string, item string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ini.Exists(section, item)
+}
+
+// SectionExists is the locked version of Ini.SectionExists
+func (s *SafeIni) SectionExists(section string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ini.SectionExists(section)
+}
+
+// GetSections is the locked version of Ini.GetSections
+func (s *SafeIni) GetSections() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ini.GetSections()
+}
+
+// GetItems is the locked version of Ini.GetItems
+func (s *SafeIni) GetItems(section string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ini.GetItems(section)
+}
+
+// Set is the locked version of Ini.Set
+func (s *SafeIni) Set(section string, item string, value string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ini.Set(section, item, value)
+}
+
+// SetAll is the locked version of Ini.SetAll
+func (s *SafeIni) SetAll(section string, item string, values []string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ini.SetAll(section, item, values)
+}
+
+// SetOrCreate is the locked version of Ini.SetOrCreate
+func (s *SafeIni) SetOrCreate(section string, item string, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ini.SetOrCreate(section, item, value)
+}
+
+// AddSection is the locked version of Ini.AddSection
+func (s *SafeIni) AddSection(section string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ini.AddSection(section)
+}
+
+// AddItem is the locked version of Ini.AddItem
+func (s *SafeIni) AddItem(section string, item string, value string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ini.AddItem(section, item, value)
+}
+
+// DeleteSection is the locked version of Ini.DeleteSection
+func (s *SafeIni) DeleteSection(section string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ini.DeleteSection(section)
+}
+
+// DeleteItem is the locked version of Ini.DeleteItem
+func (s *SafeIni) DeleteItem(section string, item string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ini.DeleteItem(section, item)
+}
+
+// RenameSection is the locked version of Ini.RenameSection
+func (s *SafeIni) RenameSection(oldName string, newName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ini.RenameSection(oldName, newName)
+}
+
+// RenameItem is the locked version of Ini.RenameItem
+func (s *SafeIni) RenameItem(section string, oldName string, newName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ini.RenameItem(section, oldName, newName)
+}
+
+// Sprint is the locked version of Ini.Sprint
+func (s *SafeIni) Sprint() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ini.Sprint()
+}
+
+// WriteTo is the locked version of Ini.WriteTo
+func (s *SafeIni) WriteTo(w io.Writer) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ini.WriteTo(w)
+}
+
+// Save is the locked version of Ini.Save
+func (s *SafeIni) Save(params ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ini.Save(params...)
+}
+
+// LoadFromFile is the locked version of Ini.LoadFromFile
+func (s *SafeIni) LoadFromFile(filename string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ini.LoadFromFile(filename)
+}
+
+/*
+Watch is the locked version of Ini.Watch, the new content is swapped in holding the write lock
+and fn receives snapshots of the previous and the new content
+*/
+func (s *SafeIni) Watch(ctx context.Context, fn func(old, new *Snapshot, changes []Change)) error {
+	s.mu.RLock()
+	watched := *s.ini // the settings and loaded files, read by the watching goroutine only
+	s.mu.RUnlock()
+
+	return watched.watch(ctx, func(next *Ini) {
+		s.mu.Lock()
+		old := s.ini.Snapshot()
+		s.ini.replaceData(next)
+		current := s.ini.Snapshot()
+		s.mu.Unlock()
+
+		if changes := changes(old.ini, current.ini); len(changes) > 0 {
+			fn(old, current, changes)
+		}
+	})
+}
+
+// Snapshot is an immutable copy of an Ini, safe to read from several goroutines
+type Snapshot struct {
+	ini *Ini
+}
+
+// Snapshot returns an immutable deep copy of the current content
+func (ini *Ini) Snapshot() *Snapshot {
+	return &Snapshot{ini.clone()}
+}
+
+// clone returns a deep copy of ini
+func (ini *Ini) clone() *Ini {
+	c := *ini
+	c.data = make(map[string]Section, len(ini.data))
+	for name, section := range ini.data {
+		items := make(map[string]Item, len(section.items))
+		for itemName, item := range section.items {
+			values := make([]itemValue, len(item.values))
+			for i, v := range item.values {
+				v.lead = copyStrings(v.lead)
+				values[i] = v
+			}
+			item.values = values
+			item.comments = copyStrings(item.comments)
+			item.lead = copyStrings(item.lead)
+			item.directives = copyStrings(item.directives)
+			items[itemName] = item
+		}
+		section.items = items
+		section.order = copyStrings(section.order)
+		section.comments = copyStrings(section.comments)
+		section.lead = copyStrings(section.lead)
+		section.directives = copyStrings(section.directives)
+		c.data[name] = section
+	}
+	c.order = copyStrings(ini.order)
+	c.trailing = copyStrings(ini.trailing)
+	c.loaded = copyStrings(ini.loaded)
+	c.warnings = append([]*ParseError(nil), ini.warnings...)
+	return &c
+}
+
+// copyStrings returns a copy of a, nil if a is nil
+func copyStrings(a []string) []string {
+	if a == nil {
+		return nil
+	}
+	return append(make([]string, 0, len(a)), a...)
+}
+
+// Filename returns the file of the copied Ini
+func (s *Snapshot) Filename() string {
+	return s.ini.Filename
+}
+
+// Get returns the value of an item, see Ini.Get
+func (s *Snapshot) Get(section string, item string) (string, bool) {
+	return s.ini.Get(section, item)
+}
+
+// GetAll returns all the values of an item, see Ini.GetAll
+func (s *Snapshot) GetAll(section string, item string) ([]string, bool) {
+	return s.ini.GetAll(section, item)
+}
+
+// GetExpanded returns the value of an item with its references expanded, see Ini.GetExpanded
+func (s *Snapshot) GetExpanded(section string, item string) (string, error) {
+	return s.ini.GetExpanded(section, item)
+}
+
+// Exists returns true if the item exists
+func (s *Snapshot) Exists(section string, item string) bool {
+	return s.ini.Exists(section, item)
+}
+
+// SectionExists returns true if the section exists
+func (s *Snapshot) SectionExists(section string) bool {
+	return s.ini.SectionExists(section)
+}
+
+// GetSections returns all the sections, in document order
+func (s *Snapshot) GetSections() []string {
+	return s.ini.GetSections()
+}
+
+// GetItems returns all the items of a section, in document order
+func (s *Snapshot) GetItems(section string) []string {
+	return s.ini.GetItems(section)
+}
+
+// GetSectionComments returns the comments of a section
+func (s *Snapshot) GetSectionComments(section string) []string {
+	return copyStrings(s.ini.GetSectionComments(section))
+}
+
+// GetItemComments returns the comments of an item
+func (s *Snapshot) GetItemComments(section string, item string) []string {
+	return copyStrings(s.ini.GetItemComments(section, item))
+}
+
+// GetInt returns the value of an item as an int, see Ini.GetInt
+func (s *Snapshot) GetInt(section string, item string) (int, error) {
+	return s.ini.GetInt(section, item)
+}
+
+// GetIntDefault returns the value of an item as an int, or def
+func (s *Snapshot) GetIntDefault(section string, item string, def int) int {
+	return s.ini.GetIntDefault(section, item, def)
+}
+
+// GetInt64 returns the value of an item as an int64, see Ini.GetInt64
+func (s *Snapshot) GetInt64(section string, item string) (int64, error) {
+	return s.ini.GetInt64(section, item)
+}
+
+// GetInt64Default returns the value of an item as an int64, or def
+func (s *Snapshot) GetInt64Default(section string, item string, def int64) int64 {
+	return s.ini.GetInt64Default(section, item, def)
+}
+
+// GetUint returns the value of an item as an uint, see Ini.GetUint
+func (s *Snapshot) GetUint(section string, item string) (uint, error) {
+	return s.ini.GetUint(section, item)
+}
+
+// GetUintDefault returns the value of an item as an uint, or def
+func (s *Snapshot) GetUintDefault(section string, item string, def uint) uint {
+	return s.ini.GetUintDefault(section, item, def)
+}
+
+// GetFloat64 returns the value of an item as a float64, see Ini.GetFloat64
+func (s *Snapshot) GetFloat64(section string, item string) (float64, error) {
+	return s.ini.GetFloat64(section, item)
+}
+
+// GetFloat64Default returns the value of an item as a float64, or def
+func (s *Snapshot) GetFloat64Default(section string, item string, def float64) float64 {
+	return s.ini.GetFloat64Default(section, item, def)
+}
+
+// GetBool returns the value of an item as a bool, see Ini.GetBool
+func (s *Snapshot) GetBool(section string, item string) (bool, error) {
+	return s.ini.GetBool(section, item)
+}
+
+// GetBoolDefault returns the value of an item as a bool, or def
+func (s *Snapshot) GetBoolDefault(section string, item string, def bool) bool {
+	return s.ini.GetBoolDefault(section, item, def)
+}
+
+// GetDuration returns the value of an item as a time.Duration, see Ini.GetDuration
+func (s *Snapshot) GetDuration(section string, item string) (time.Duration, error) {
+	return s.ini.GetDuration(section, item)
+}
+
+// GetDurationDefault returns the value of an item as a time.Duration, or def
+func (s *Snapshot) GetDurationDefault(section string, item string, def time.Duration) time.Duration {
+	return s.ini.GetDurationDefault(section, item, def)
+}
+
+// GetByteSize returns the value of an item as a number of bytes, see Ini.GetByteSize
+func (s *Snapshot) GetByteSize(section string, item string) (uint64, error) {
+	return s.ini.GetByteSize(section, item)
+}
+
+// GetByteSizeDefault returns the value of an item as a number of bytes, or def
+func (s *Snapshot) GetByteSizeDefault(section string, item string, def uint64) uint64 {
+	return s.ini.GetByteSizeDefault(section, item, def)
+}
+
+// MapTo stores the values in the struct pointed to by v, see Ini.MapTo
+func (s *Snapshot) MapTo(v interface{}) error {
+	return s.ini.MapTo(v)
+}
+
+// Sprint returns the ini format into a formatted string
+func (s *Snapshot) Sprint() string {
+	return s.ini.Sprint()
+}
+
+// WriteTo writes the ini format to w
+func (s *Snapshot) WriteTo(w io.Writer) (int64, error) {
+	return s.ini.WriteTo(w)
+}
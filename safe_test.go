@@ -0,0 +1,50 @@
+package ini
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSafeIni(t *testing.T) {
+	myIni := new(Ini)
+	content := "[Server]\nhost=localhost\n"
+	myIni.LoadFromString(&content)
+	safe := NewSafeIni(myIni)
+	snapshot := safe.Snapshot()
+
+	// concurrent readers and writers, run with -race ///////////////////////////////////////////////////////
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			safe.SetOrCreate("Server", "item"+strconv.Itoa(i), strconv.Itoa(i))
+			safe.Set("Server", "host", "host"+strconv.Itoa(i))
+		}(i)
+		go func() {
+			defer wg.Done()
+			safe.Get("Server", "host")
+			safe.Sprint()
+			safe.Read(func(ini *Ini) { ini.GetIntDefault("Server", "item0", 0) })
+		}()
+	}
+	wg.Wait()
+
+	if n := len(safe.GetItems("Server")); n != 11 {
+		t.Error("For", "GetItems(Server)", "expected", 11, "got", n)
+	}
+
+	// the snapshot does not change ///////////////////////////////////////////////////////
+	if s, _ := snapshot.Get("Server", "host"); s != "localhost" {
+		t.Error("For", "Snapshot.Get(Server,host)", "expected", "localhost", "got", s)
+	}
+	if n := len(snapshot.GetItems("Server")); n != 1 {
+		t.Error("For", "Snapshot.GetItems(Server)", "expected", 1, "got", n)
+	}
+	comments := myIni.Snapshot().GetItemComments("Server", "host")
+	safe.Update(func(ini *Ini) { ini.AddItemComment("Server", "host", "changed") })
+	if len(comments) != 0 {
+		t.Error("For", "Snapshot.GetItemComments(Server,host)", "expected", 0, "got", len(comments))
+	}
+}
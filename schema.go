@@ -0,0 +1,619 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ItemType is the type of the values of an item in a Schema
+type ItemType string
+
+// Item types, the name is the type of a schema file
+const (
+	TypeString   ItemType = "string"   // any value
+	TypeInt      ItemType = "int"      // integer
+	TypeFloat    ItemType = "float"    // floating point number
+	TypeBool     ItemType = "bool"     // boolean, like GetBool
+	TypeDuration ItemType = "duration" // duration, like GetDuration
+	TypeByteSize ItemType = "size"     // number of bytes, like GetByteSize
+	TypeEnum     ItemType = "enum"     // one of Values
+	TypeRegexp   ItemType = "regexp"   // string matching Pattern
+	TypeURL      ItemType = "url"      // absolute URL, with a scheme and a host
+	TypeHostPort ItemType = "hostport" // host:port, the host may be empty
+	TypePath     ItemType = "path"     // file path, existing if MustExist is set
+)
+
+// itemTypes are the known item types
+var itemTypes = map[ItemType]bool{
+	"": true, TypeString: true, TypeInt: true, TypeFloat: true, TypeBool: true, TypeDuration: true, TypeByteSize: true,
+	TypeEnum: true, TypeRegexp: true, TypeURL: true, TypeHostPort: true, TypePath: true,
+}
+
+// Schema declares the sections and items expected in a document, see Validate
+type Schema struct {
+	Sections []SectionSchema
+
+	// If set to true, sections and items not declared are violations
+	Strict bool
+}
+
+// SectionSchema declares a section, the section "" holds the items outside any section
+type SectionSchema struct {
+	Name     string
+	Required bool
+	Items    []ItemSchema
+}
+
+// ItemSchema declares an item, every value of the item is checked
+type ItemSchema struct {
+	Name     string
+	Type     ItemType // TypeString if empty
+	Required bool
+
+	// Value set by SetDefaults when the item is missing
+	Default string
+
+	// Range of the int, float and size values, nil for no bound
+	Min *float64
+	Max *float64
+
+	// Allowed values of an enum
+	Values []string
+
+	// Regular expression the value must match, for every type
+	Pattern string
+	pattern *regexp.Regexp // Pattern compiled by SchemaFromIni and SchemaFromStruct
+
+	// If set to true, a path must exist
+	MustExist bool
+
+	// If set to true, a single value is a comma separated list and each element is checked, like MapTo
+	// does for a slice field
+	List bool
+}
+
+// ValidationError is a violation of a Schema
+type ValidationError struct {
+	// Name of the file of the line, empty when loaded from a string
+	Filename string
+
+	// Line of the item or the section, 0 when missing or set by the program
+	Line int
+
+	Section string
+	Item    string // empty for a section violation
+
+	// What is wrong
+	Reason string
+}
+
+// Error returns the error as "filename:line: section.item: reason"
+func (e *ValidationError) Error() string {
+	name := e.Section
+	if e.Item != "" {
+		name += "." + e.Item
+	}
+	position := ""
+	if e.Line > 0 {
+		position = strconv.Itoa(e.Line) + ": "
+		if e.Filename != "" {
+			position = e.Filename + ":" + position
+		}
+	}
+	return position + name + ": " + e.Reason
+}
+
+// ValidationErrors is the list of violations returned by Validate
+type ValidationErrors []*ValidationError
+
+// Error returns the errors, one per line
+func (e ValidationErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+/*
+Validate checks the document against schema, returns every violation as ValidationErrors, nil if none
+
+Example :
+
+	schema, err := ini.LoadSchemaFile("app.schema.ini")
+	...
+	if err := myIni.Validate(schema); err != nil {
+		for _, violation := range err.(ini.ValidationErrors) {
+			print(violation.Error(), "\n")
+		}
+	}
+*/
+func (ini *Ini) Validate(schema *Schema) error {
+	errs := make(ValidationErrors, 0)
+	add := func(pos position, section string, item string, reason string) {
+		errs = append(errs, &ValidationError{Filename: pos.filename, Line: pos.line, Section: section, Item: item, Reason: reason})
+	}
+
+	declared := make(map[string]*SectionSchema)
+	for i := range schema.Sections {
+		s := &schema.Sections[i]
+		declared[s.Name] = s
+
+		if !ini.SectionExists(s.Name) && s.Name != "" { // its required items are required only in the section
+			if s.Required {
+				add(position{}, s.Name, "", "missing required section")
+			}
+			continue
+		}
+
+		sectionPosition := ini.data[s.Name].position
+		for _, item := range s.Items {
+			values, exists := ini.GetAll(s.Name, item.Name)
+			if !exists {
+				if item.Required {
+					add(sectionPosition, s.Name, item.Name, "missing required item")
+				}
+				continue
+			}
+			positions := ini.data[s.Name].items[item.Name].values
+			for j, value := range values {
+				pos := sectionPosition
+				if j < len(positions) {
+					pos = positions[j].position
+				}
+				elements := []string{value}
+				if item.List && len(values) == 1 { // comma separated values
+					elements = listElements(value)
+				}
+				for _, element := range elements {
+					if reason := item.check(element); reason != "" {
+						add(pos, s.Name, item.Name, reason)
+					}
+				}
+			}
+		}
+	}
+
+	if schema.Strict {
+		for _, section := range ini.GetSections() {
+			s, ok := declared[section]
+			if !ok {
+				add(ini.data[section].position, section, "", "unknown section")
+				continue
+			}
+			items := make(map[string]bool, len(s.Items))
+			for _, item := range s.Items {
+				items[item.Name] = true
+			}
+			for _, item := range ini.GetItems(section) {
+				if !items[item] {
+					pos := ini.data[section].position
+					if values := ini.data[section].items[item].values; len(values) > 0 {
+						pos = values[0].position
+					}
+					add(pos, section, item, "unknown item")
+				}
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// check returns why value does not match the item schema, empty if it does
+func (s ItemSchema) check(value string) string {
+	var number float64
+	var err error
+
+	switch s.Type {
+	case "", TypeString:
+	case TypeInt:
+		var i int64
+		i, err = strconv.ParseInt(value, 10, 64)
+		number = float64(i)
+	case TypeFloat:
+		number, err = strconv.ParseFloat(value, 64)
+	case TypeBool:
+		_, err = parseBool(value)
+	case TypeDuration:
+		_, err = time.ParseDuration(value)
+	case TypeByteSize:
+		var size uint64
+		size, err = parseByteSize(value)
+		number = float64(size)
+	case TypeEnum:
+		if !containsName(s.Values, value) {
+			return fmt.Sprintf("%q is not one of %s", value, strings.Join(s.Values, ", "))
+		}
+	case TypeRegexp:
+		if s.Pattern == "" {
+			return "no pattern to match"
+		}
+	case TypeURL:
+		var u *url.URL
+		u, err = url.Parse(value)
+		if err == nil && (u.Scheme == "" || u.Host == "") {
+			err = errors.New("missing scheme or host")
+		}
+	case TypeHostPort:
+		var port string
+		_, port, err = net.SplitHostPort(value)
+		if err == nil {
+			if p, e := strconv.ParseUint(port, 10, 16); e != nil || p == 0 {
+				err = errors.New("invalid port " + strconv.Quote(port))
+			}
+		}
+	case TypePath:
+		if value == "" || strings.ContainsRune(value, 0) {
+			err = errors.New("empty or invalid path")
+		} else if s.MustExist {
+			_, err = os.Stat(value)
+		}
+	default:
+		return "unknown type " + string(s.Type)
+	}
+	if err != nil {
+		return fmt.Sprintf("%q is not a valid %s: %v", value, s.Type, err)
+	}
+
+	switch s.Type {
+	case TypeInt, TypeFloat, TypeByteSize:
+		if s.Min != nil && number < *s.Min {
+			return fmt.Sprintf("%s is less than %v", value, *s.Min)
+		}
+		if s.Max != nil && number > *s.Max {
+			return fmt.Sprintf("%s is greater than %v", value, *s.Max)
+		}
+	}
+
+	if s.Pattern != "" {
+		re := s.pattern
+		if re == nil || re.String() != s.Pattern { // declared or changed by the program
+			if re, err = regexp.Compile(s.Pattern); err != nil {
+				return "invalid pattern: " + err.Error()
+			}
+		}
+		if !re.MatchString(value) {
+			return fmt.Sprintf("%q does not match %s", value, s.Pattern)
+		}
+	}
+	return ""
+}
+
+// SetDefaults adds the missing items having a default value in schema, creating their section if needed
+func (ini *Ini) SetDefaults(schema *Schema) {
+	for _, s := range schema.Sections {
+		for _, item := range s.Items {
+			if item.Default != "" && !ini.ItemExists(s.Name, item.Name) {
+				ini.AddItem(s.Name, item.Name, item.Default)
+			}
+		}
+	}
+	ini.topFirst()
+}
+
+/*
+SchemaFromStruct returns the schema of the struct v (or pointer to struct), mapped like MapTo does
+
+The type of an item comes from its field : int for ints and uints, float, bool, duration for time.Duration,
+string for the others. These tags complete the declaration :
+
+	required:"true"     the section or the item is required
+	default:"value"     default value, also used by MapTo
+	type:"url"          type of the item, like url, hostport or path
+	enum:"a,b,c"        allowed values, the type is enum
+	pattern:"^[a-z]+$"  regular expression the values must match
+	min:"1" max:"10"    range of the values
+	exists:"true"       the path must exist
+
+Example :
+
+	type Config struct {
+		Server struct {
+			Listen string `ini:"listen" type:"hostport" required:"true"`
+			Mode   string `ini:"mode" enum:"dev,prod" default:"prod"`
+		} `required:"true"`
+	}
+
+	schema, err := ini.SchemaFromStruct(Config{})
+*/
+func SchemaFromStruct(v interface{}) (*Schema, error) {
+	rt := reflect.TypeOf(v)
+	for rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil, errors.New("ini: SchemaFromStruct needs a struct or a pointer to a struct")
+	}
+
+	schema := new(Schema)
+	top := SectionSchema{Name: ""}
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := parseFieldTag(field)
+		if tag.skip || field.PkgPath != "" {
+			continue
+		}
+
+		if !isSection(field.Type) {
+			item, err := fieldSchema(tag.name, field)
+			if err != nil {
+				return nil, err
+			}
+			top.Items = append(top.Items, item)
+			continue
+		}
+
+		st := field.Type
+		if st.Kind() == reflect.Ptr {
+			st = st.Elem()
+		}
+		section := SectionSchema{Name: tag.name, Required: field.Tag.Get("required") == "true"}
+		for j := 0; j < st.NumField(); j++ {
+			itemField := st.Field(j)
+			itemTag := parseFieldTag(itemField)
+			if itemTag.skip || itemField.PkgPath != "" {
+				continue
+			}
+			item, err := fieldSchema(itemTag.name, itemField)
+			if err != nil {
+				return nil, fmt.Errorf("ini: %s: %w", tag.name, err)
+			}
+			section.Items = append(section.Items, item)
+		}
+		schema.Sections = append(schema.Sections, section)
+	}
+
+	if len(top.Items) > 0 {
+		schema.Sections = append([]SectionSchema{top}, schema.Sections...)
+	}
+	return schema, nil
+}
+
+// fieldSchema returns the schema of the item of a field
+func fieldSchema(name string, field reflect.StructField) (ItemSchema, error) {
+	item := ItemSchema{
+		Name:      name,
+		Type:      ItemType(field.Tag.Get("type")),
+		Required:  field.Tag.Get("required") == "true",
+		Default:   field.Tag.Get("default"),
+		Pattern:   field.Tag.Get("pattern"),
+		MustExist: field.Tag.Get("exists") == "true",
+		List:      field.Type.Kind() == reflect.Slice && !reflect.PtrTo(field.Type).Implements(textUnmarshalerType), // split by MapTo
+	}
+	if enum, ok := field.Tag.Lookup("enum"); ok {
+		item.Type = TypeEnum
+		item.Values = splitList(enum)
+	}
+
+	if item.Type == "" {
+		t := field.Type
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice && !t.Implements(textMarshalerType) {
+			t = t.Elem()
+		}
+		switch {
+		case t == durationType:
+			item.Type = TypeDuration
+		case reflect.PtrTo(t).Implements(textUnmarshalerType):
+			item.Type = TypeString
+		default:
+			switch t.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				item.Type = TypeInt
+			case reflect.Float32, reflect.Float64:
+				item.Type = TypeFloat
+			case reflect.Bool:
+				item.Type = TypeBool
+			default:
+				item.Type = TypeString
+			}
+		}
+	}
+
+	if !itemTypes[item.Type] {
+		return item, fmt.Errorf("%s: unknown type %q", name, item.Type)
+	}
+
+	var err error
+	if item.pattern, err = regexp.Compile(item.Pattern); err != nil {
+		return item, fmt.Errorf("%s: invalid pattern: %w", name, err)
+	}
+	if item.Min, err = parseBound(field.Tag.Get("min")); err != nil {
+		return item, fmt.Errorf("%s: invalid min: %w", name, err)
+	}
+	if item.Max, err = parseBound(field.Tag.Get("max")); err != nil {
+		return item, fmt.Errorf("%s: invalid max: %w", name, err)
+	}
+	return item, nil
+}
+
+// parseBound returns the bound of a range, nil if empty
+func parseBound(value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	bound, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &bound, nil
+}
+
+// listElements returns the elements of a list value, none if it is empty
+func listElements(value string) []string {
+	elements := splitList(value)
+	if len(elements) == 1 && elements[0] == "" {
+		return nil
+	}
+	return elements
+}
+
+// splitList splits a comma separated list, trimming the elements
+func splitList(value string) []string {
+	values := strings.Split(value, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+	return values
+}
+
+/*
+LoadSchemaFile reads a schema file, see SchemaFromIni
+
+Example :
+
+	schema, err := ini.LoadSchemaFile("app.schema.ini")
+*/
+func LoadSchemaFile(filename string) (*Schema, error) {
+	schemaIni := new(Ini)
+	if err := schemaIni.LoadFromFile(filename); err != nil {
+		return nil, err
+	}
+	return SchemaFromIni(schemaIni)
+}
+
+/*
+SchemaFromIni returns the schema described by an ini document. A [section] declares a section, a
+[section:item] declares an item of section ([:item] for an item outside any section), with these items :
+
+	type = int          type of the item (string by default)
+	required = true     the section or the item is required
+	default = 80        default value
+	min = 1             range of the values
+	max = 65535
+	values = dev,prod   allowed values, the type is enum
+	pattern = ^[a-z]+$  regular expression the values must match
+	exists = true       the path must exist
+	list = true         a single value is a comma separated list
+
+The item strict = true, outside any section, rejects the sections and items not declared
+
+Example :
+
+	strict = true
+
+	[Server]
+	required = true
+
+	[Server:port]
+	type = int
+	min = 1
+	max = 65535
+	default = 80
+*/
+func SchemaFromIni(schemaIni *Ini) (*Schema, error) {
+	schema := new(Schema)
+	indexes := make(map[string]int) // index of each section in schema.Sections
+	section := func(name string) *SectionSchema {
+		i, ok := indexes[name]
+		if !ok {
+			i = len(schema.Sections)
+			indexes[name] = i
+			schema.Sections = append(schema.Sections, SectionSchema{Name: name})
+		}
+		return &schema.Sections[i]
+	}
+	fail := func(name string, reason string) error {
+		pos := schemaIni.data[name].position
+		return &ValidationError{Filename: pos.filename, Line: pos.line, Section: name, Reason: reason}
+	}
+
+	for _, name := range schemaIni.GetSections() {
+		get := func(item string) string {
+			value, _ := schemaIni.Get(name, item)
+			return value
+		}
+
+		if name == "" {
+			for _, item := range schemaIni.GetItems(name) {
+				if item != "strict" {
+					return nil, fail(name, "unknown schema option "+strconv.Quote(item))
+				}
+			}
+			strict, err := parseBool(get("strict"))
+			if err != nil && schemaIni.ItemExists(name, "strict") {
+				return nil, fail(name, "invalid strict: "+err.Error())
+			}
+			schema.Strict = strict
+			continue
+		}
+
+		colon := strings.LastIndex(name, ":")
+		for _, item := range schemaIni.GetItems(name) {
+			switch item {
+			case "required":
+			case "type", "default", "min", "max", "values", "pattern", "exists", "list":
+				if colon >= 0 {
+					continue
+				}
+				fallthrough
+			default:
+				return nil, fail(name, "unknown schema option "+strconv.Quote(item))
+			}
+		}
+		required, err := parseBool(get("required"))
+		if err != nil && schemaIni.ItemExists(name, "required") {
+			return nil, fail(name, "invalid required: "+err.Error())
+		}
+
+		if colon < 0 {
+			section(name).Required = required
+			continue
+		}
+
+		item := ItemSchema{
+			Name:     name[colon+1:],
+			Type:     ItemType(get("type")),
+			Required: required,
+			Default:  get("default"),
+			Pattern:  get("pattern"),
+		}
+		if item.MustExist, err = parseBool(get("exists")); err != nil && schemaIni.ItemExists(name, "exists") {
+			return nil, fail(name, "invalid exists: "+err.Error())
+		}
+		if item.List, err = parseBool(get("list")); err != nil && schemaIni.ItemExists(name, "list") {
+			return nil, fail(name, "invalid list: "+err.Error())
+		}
+		if item.pattern, err = regexp.Compile(item.Pattern); err != nil {
+			return nil, fail(name, "invalid pattern: "+err.Error())
+		}
+		if values, ok := schemaIni.Get(name, "values"); ok {
+			item.Type = TypeEnum
+			item.Values = splitList(values)
+		}
+		if item.Min, err = parseBound(get("min")); err != nil {
+			return nil, fail(name, "invalid min: "+err.Error())
+		}
+		if item.Max, err = parseBound(get("max")); err != nil {
+			return nil, fail(name, "invalid max: "+err.Error())
+		}
+		if !itemTypes[item.Type] {
+			return nil, fail(name, "unknown type "+strconv.Quote(string(item.Type)))
+		}
+		defaults := []string{item.Default}
+		if item.List { // each element is checked, like the values
+			defaults = listElements(item.Default)
+		}
+		for _, value := range defaults {
+			if reason := item.check(value); item.Default != "" && reason != "" {
+				return nil, fail(name, "invalid default: "+reason)
+			}
+		}
+
+		s := section(name[:colon])
+		s.Items = append(s.Items, item)
+	}
+	return schema, nil
+}
@@ -0,0 +1,163 @@
+package ini
+
+import (
+	"strings"
+	"testing"
+)
+
+const schemaContent = `strict = true
+
+[Server]
+required = true
+
+[Server:listen]
+type = hostport
+required = true
+
+[Server:port]
+type = int
+min = 1
+max = 65535
+default = 80
+
+[Server:mode]
+values = dev,prod
+
+[Server:url]
+type = url
+
+[Server:name]
+type = regexp
+pattern = ^[a-z]+$
+
+[Server:debug]
+type = bool
+
+[Log]
+required = true
+`
+
+func TestValidate(t *testing.T) {
+	schemaIni := new(Ini)
+	content := schemaContent
+	schemaIni.LoadFromString(&content)
+	schema, err := SchemaFromIni(schemaIni)
+	if err != nil {
+		t.Fatal("For", "SchemaFromIni", "got", err)
+	}
+
+	myIni := new(Ini)
+	content = "[Server]\nport=70000\nmode=test\nurl=localhost\nname=Web1\ndebug=maybe\ntypo=1\n[Extra]\n"
+	myIni.LoadFromString(&content)
+
+	err = myIni.Validate(schema)
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatal("For", "Validate", "expected", "ValidationErrors", "got", err)
+	}
+	expected := []struct {
+		line          int
+		section, item string
+	}{
+		{1, "Server", "listen"}, // missing, reported on the section header line
+		{2, "Server", "port"},
+		{3, "Server", "mode"},
+		{4, "Server", "url"},
+		{5, "Server", "name"},
+		{6, "Server", "debug"},
+		{0, "Log", ""},
+		{7, "Server", "typo"},
+		{8, "Extra", ""},
+	}
+	if len(errs) != len(expected) {
+		t.Fatal("For", "Validate", "expected", len(expected), "violations", "got", errs.Error())
+	}
+	for i, e := range expected {
+		if errs[i].Line != e.line || errs[i].Section != e.section || errs[i].Item != e.item {
+			t.Error("For", "Validate", i, "expected", e, "got", errs[i].Error())
+		}
+	}
+
+	// valid document with defaults ///////////////////////////////////////////////////////
+	content = "[Server]\nlisten=:8080\nmode=prod\nurl=https://example.com/\nname=web\ndebug=yes\n[Log]\n"
+	myIni.LoadFromString(&content)
+	myIni.SetDefaults(schema)
+	if err := myIni.Validate(schema); err != nil {
+		t.Error("For", "Validate", "expected", nil, "got", err)
+	}
+	if s, _ := myIni.Get("Server", "port"); s != "80" {
+		t.Error("For", "SetDefaults", "expected", "80", "got", s)
+	}
+
+	// invalid schema ///////////////////////////////////////////////////////
+	content = "[Server:port]\ntype = integer\n"
+	schemaIni.LoadFromString(&content)
+	if _, err := SchemaFromIni(schemaIni); err == nil {
+		t.Error("For", "SchemaFromIni(unknown type)", "expected", "error", "got", nil)
+	}
+	invalid := map[string]string{
+		"list":    "[Server:ports]\nlist = maybe\n",
+		"exists":  "[Server:root]\nexists = sometimes\n",
+		"pattern": "[Server:name]\npattern = [a-z\n",
+		"default": "[Server:ports]\ntype = int\nlist = yes\ndefault = 80,x\n",
+	}
+	for option, content := range invalid {
+		schemaIni.LoadFromString(&content)
+		if _, err := SchemaFromIni(schemaIni); err == nil || !strings.Contains(err.Error(), "invalid "+option) {
+			t.Error("For", "SchemaFromIni(invalid "+option+")", "expected", "error", "got", err)
+		}
+	}
+
+	// list default ///////////////////////////////////////////////////////
+	content = "[Server:ports]\ntype = int\nlist = yes\ndefault = 80,443\n"
+	schemaIni.LoadFromString(&content)
+	schema, err = SchemaFromIni(schemaIni)
+	if err != nil {
+		t.Fatal("For", "SchemaFromIni(list default)", "expected", nil, "got", err)
+	}
+	content = "[Server]\n"
+	myIni.LoadFromString(&content)
+	myIni.SetDefaults(schema)
+	if err := myIni.Validate(schema); err != nil {
+		t.Error("For", "Validate(list default)", "expected", nil, "got", err)
+	}
+}
+
+func TestSchemaFromStruct(t *testing.T) {
+	type config struct {
+		Name   string `ini:"name" required:"true"`
+		Server struct {
+			Listen string `ini:"listen" type:"hostport" required:"true"`
+			Port   int    `ini:"port" min:"1" max:"65535"`
+			Mode   string `ini:"mode" enum:"dev,prod" default:"prod"`
+			Ports  []int  `ini:"ports"`
+		} `required:"true"`
+	}
+	schema, err := SchemaFromStruct(config{})
+	if err != nil {
+		t.Fatal("For", "SchemaFromStruct", "got", err)
+	}
+
+	myIni := new(Ini)
+	content := "[Server]\nlisten=localhost\nport=0\nmode=test\nports=80, 443\n"
+	myIni.LoadFromString(&content)
+	err = myIni.Validate(schema)
+	errs, _ := err.(ValidationErrors)
+	expected := []string{"name", "listen", "port", "mode"}
+	if len(errs) != len(expected) {
+		t.Fatal("For", "Validate", "expected", expected, "got", err)
+	}
+	for i, item := range expected {
+		if errs[i].Item != item {
+			t.Error("For", "Validate", i, "expected", item, "got", errs[i].Error())
+		}
+	}
+
+	// a slice field is a comma separated list, like MapTo reads it
+	content = "name=app\n[Server]\nlisten=:80\nports=80,x\n"
+	myIni.LoadFromString(&content)
+	err = myIni.Validate(schema)
+	if errs, _ = err.(ValidationErrors); len(errs) != 1 || errs[0].Item != "ports" || !strings.Contains(errs[0].Reason, `"x"`) {
+		t.Error("For", "Validate(ports=80,x)", "expected", "an error on x", "got", err)
+	}
+}
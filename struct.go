@@ -0,0 +1,397 @@
+package ini
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// fieldTag is the parsed `ini:"name,options"` tag of a struct field
+type fieldTag struct {
+	name      string
+	omitEmpty bool
+	skip      bool
+}
+
+// parseFieldTag returns the name and options of a struct field, the field name is used if the tag has none
+func parseFieldTag(field reflect.StructField) fieldTag {
+	tag := field.Tag.Get("ini")
+	if tag == "-" {
+		return fieldTag{skip: true}
+	}
+	parts := strings.Split(tag, ",")
+	result := fieldTag{name: parts[0]}
+	if result.name == "" {
+		result.name = field.Name
+	}
+	for _, option := range parts[1:] {
+		if option == "omitempty" {
+			result.omitEmpty = true
+		}
+	}
+	return result
+}
+
+// isSection returns true if a field of this type is mapped to a section rather than an item
+func isSection(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+/*
+Unmarshal parses ini data and stores the values in the struct pointed to by v, see MapTo
+
+Example :
+
+	var cfg Config
+	err := ini.Unmarshal(content, &cfg)
+*/
+func Unmarshal(data []byte, v interface{}) error {
+	ini := new(Ini)
+	content := string(data)
+	if err := ini.LoadFromString(&content); err != nil {
+		return err
+	}
+	return ini.MapTo(v)
+}
+
+/*
+MapTo stores the values in the struct pointed to by v
+
+Each field of type struct (or pointer to struct) is a section, the other fields are the items of the "" section.
+The fields of a section are its items. The name is given by the `ini:"name"` tag (the field name by default),
+`ini:"-"` skips the field. When an item does not exists, the `default:"value"` tag is used if present.
+
+Supported types are strings, bools, ints, uints, floats, time.Duration, types implementing
+encoding.TextUnmarshaler (like time.Time), pointers to them and slices of them (an item with several values,
+or a comma separated value)
+
+Example :
+
+	type Config struct {
+		Server struct {
+			Host    string        `ini:"host" default:"localhost"`
+			Port    int           `ini:"port" default:"80"`
+			Timeout time.Duration `ini:"timeout"`
+		}
+	}
+
+	var cfg Config
+	err := myIni.MapTo(&cfg)
+*/
+func (ini *Ini) MapTo(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("ini: MapTo needs a non nil pointer to a struct")
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := parseFieldTag(field)
+		if tag.skip || field.PkgPath != "" { // unexported field
+			continue
+		}
+
+		if isSection(field.Type) {
+			fv := rv.Field(i)
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					fv.Set(reflect.New(field.Type.Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if err := ini.mapSection(tag.name, fv); err != nil {
+				return err
+			}
+		} else if err := ini.mapItem("", tag.name, field, rv.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mapSection stores the items of a section in the fields of a struct
+func (ini *Ini) mapSection(section string, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := parseFieldTag(field)
+		if tag.skip || field.PkgPath != "" {
+			continue
+		}
+		if err := ini.mapItem(section, tag.name, field, rv.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mapItem stores the values of an item (or the default value of the field) in a field
+func (ini *Ini) mapItem(section string, item string, field reflect.StructField, fv reflect.Value) error {
+	values, exists := ini.GetAll(section, item)
+	if !exists {
+		def, hasDefault := field.Tag.Lookup("default")
+		if !hasDefault {
+			return nil // keep the zero value
+		}
+		values = []string{def}
+	}
+	if err := setField(fv, values); err != nil {
+		return fmt.Errorf("ini: %s.%s: %w", section, item, err)
+	}
+	return nil
+}
+
+// setField converts the values of an item to the type of a field
+func setField(fv reflect.Value, values []string) error {
+	if fv.Kind() == reflect.Slice && !fv.Addr().Type().Implements(textUnmarshalerType) {
+		if len(values) == 1 { // comma separated values
+			values = strings.Split(values[0], ",")
+			if len(values) == 1 && strings.TrimSpace(values[0]) == "" {
+				values = nil
+			}
+		}
+		slice := reflect.MakeSlice(fv.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(slice.Index(i), strings.TrimSpace(value)); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+	return setValue(fv, values[len(values)-1])
+}
+
+// setValue converts a value to the type of v
+func setValue(v reflect.Value, value string) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return setValue(v.Elem(), value)
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return errors.New("unsupported type " + v.Type().String())
+	}
+	return nil
+}
+
+/*
+Marshal returns the ini format of the struct v, see ReflectFrom
+
+Example :
+
+	content, err := ini.Marshal(&cfg)
+*/
+func Marshal(v interface{}) ([]byte, error) {
+	ini := new(Ini)
+	empty := ""
+	ini.LoadFromString(&empty) // default formating
+	if err := ini.ReflectFrom(v); err != nil {
+		return nil, err
+	}
+	return []byte(ini.Sprint()), nil
+}
+
+/*
+ReflectFrom sets the sections and items from the fields of the struct v (or pointer to struct),
+creating them if needed
+
+The fields are mapped like MapTo does. A `comment:"text"` tag adds a comment to the section or the item
+if it has none, an `ini:"name,omitempty"` tag skips the item when the field has its zero value
+
+Example :
+
+	type Config struct {
+		Server struct {
+			Host string `ini:"host" comment:"Listening address"`
+			Port int    `ini:"port,omitempty"`
+		} `comment:"HTTP server"`
+	}
+
+	err := myIni.ReflectFrom(&Config{})
+*/
+func (ini *Ini) ReflectFrom(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return errors.New("ini: ReflectFrom needs a struct or a non nil pointer to a struct")
+	}
+	rt := rv.Type()
+
+	// items of the "" section first, they must be written before any section header
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := parseFieldTag(field)
+		if tag.skip || field.PkgPath != "" || isSection(field.Type) {
+			continue
+		}
+		if err := ini.reflectItem("", tag, field, rv.Field(i)); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		tag := parseFieldTag(field)
+		if tag.skip || field.PkgPath != "" || !isSection(field.Type) {
+			continue
+		}
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		}
+
+		ini.AddSection(tag.name)
+		if comment, exists := field.Tag.Lookup("comment"); exists && len(ini.GetSectionComments(tag.name)) == 0 {
+			ini.AddSectionComment(tag.name, comment)
+		}
+		st := fv.Type()
+		for j := 0; j < st.NumField(); j++ {
+			itemField := st.Field(j)
+			itemTag := parseFieldTag(itemField)
+			if itemTag.skip || itemField.PkgPath != "" {
+				continue
+			}
+			if err := ini.reflectItem(tag.name, itemTag, itemField, fv.Field(j)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reflectItem sets an item from a field
+func (ini *Ini) reflectItem(section string, tag fieldTag, field reflect.StructField, fv reflect.Value) error {
+	if tag.omitEmpty && (fv.IsZero() || (fv.Kind() == reflect.Slice && fv.Len() == 0)) {
+		return nil
+	}
+	values, err := fieldValues(fv)
+	if err != nil {
+		return fmt.Errorf("ini: %s.%s: %w", section, tag.name, err)
+	}
+	if len(values) == 0 {
+		values = []string{""}
+	}
+
+	ini.AddItem(section, tag.name, values[0])
+	ini.SetAll(section, tag.name, values)
+	if comment, exists := field.Tag.Lookup("comment"); exists && len(ini.GetItemComments(section, tag.name)) == 0 {
+		ini.AddItemComment(section, tag.name, comment)
+	}
+	return nil
+}
+
+// fieldValues converts a field to the values of an item, one per slice element
+func fieldValues(fv reflect.Value) ([]string, error) {
+	if fv.Kind() == reflect.Slice && !fv.Type().Implements(textMarshalerType) {
+		values := make([]string, fv.Len())
+		for i := range values {
+			value, err := formatValue(fv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	}
+	value, err := formatValue(fv)
+	if err != nil {
+		return nil, err
+	}
+	return []string{value}, nil
+}
+
+// formatValue converts v to the value of an item
+func formatValue(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		return formatValue(v.Elem())
+	}
+
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	return "", errors.New("unsupported type " + v.Type().String())
+}
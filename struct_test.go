@@ -0,0 +1,112 @@
+package ini
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Name   string `ini:"name"`
+	Server struct {
+		Host    string        `ini:"host" default:"localhost"`
+		Port    int           `ini:"port" default:"80"`
+		Debug   bool          `ini:"debug"`
+		Timeout time.Duration `ini:"timeout"`
+		Ratio   *float64      `ini:"ratio"`
+		Ignored string        `ini:"-"`
+	}
+	Upstream *struct {
+		Servers []string `ini:"server"`
+		Weights []uint8  `ini:"weights"`
+		IP      net.IP   `ini:"ip"`
+	} `ini:"upstream"`
+	Dates struct {
+		Start time.Time `ini:"start"`
+	} `ini:"dates"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	var cfg testConfig
+	content := `
+name=demo
+[Server]
+port=8080
+debug=on
+timeout=2s
+ratio=0.5
+Ignored=value
+[upstream]
+server=a
+server=b
+weights=1, 2,3
+ip=10.0.0.1
+[dates]
+start=2020-01-02T03:04:05Z
+`
+	if err := Unmarshal([]byte(content), &cfg); err != nil {
+		t.Fatal("For", "Unmarshal()", "expected", nil, "got", err)
+	}
+
+	s := fmt.Sprintf("%s %s %d %v %v %v %q", cfg.Name, cfg.Server.Host, cfg.Server.Port, cfg.Server.Debug, cfg.Server.Timeout, *cfg.Server.Ratio, cfg.Server.Ignored)
+	if expected := `demo localhost 8080 true 2s 0.5 ""`; s != expected {
+		t.Error("For", "Unmarshal(Server)", "expected", expected, "got", s)
+	}
+	s = fmt.Sprintf("%v %v %v %v", cfg.Upstream.Servers, cfg.Upstream.Weights, cfg.Upstream.IP, cfg.Dates.Start.Year())
+	if expected := "[a b] [1 2 3] 10.0.0.1 2020"; s != expected {
+		t.Error("For", "Unmarshal(upstream)", "expected", expected, "got", s)
+	}
+
+	// conversion errors ///////////////////////////////////////////////////////
+	content = "[Server]\nport=abc\n"
+	if err := Unmarshal([]byte(content), &cfg); err == nil {
+		t.Error("For", "Unmarshal(port=abc)", "expected", "an error", "got", err)
+	}
+	if err := Unmarshal([]byte(content), cfg); err == nil {
+		t.Error("For", "Unmarshal(not a pointer)", "expected", "an error", "got", err)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	type config struct {
+		Server struct {
+			Host    string        `ini:"host" comment:"Listening address"`
+			Port    int           `ini:"port,omitempty"`
+			Timeout time.Duration `ini:"timeout"`
+		} `comment:"HTTP server"`
+		Upstream *struct {
+			Servers []string `ini:"server"`
+		} `ini:"upstream"`
+		Name string `ini:"name"`
+	}
+
+	var cfg config
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.Timeout = 3 * time.Second
+	cfg.Upstream = &struct {
+		Servers []string `ini:"server"`
+	}{[]string{"a", "b"}}
+	cfg.Name = "demo"
+
+	content, err := Marshal(&cfg)
+	if err != nil {
+		t.Fatal("For", "Marshal()", "expected", nil, "got", err)
+	}
+	expected := "  name = demo\n\n" +
+		"; HTTP server\n[Server]\n  ; Listening address\n  host = 127.0.0.1\n\n  timeout = 3s\n\n" +
+		"[upstream]\n  server = a\n  server = b\n"
+	if s := string(content); s != expected {
+		t.Errorf("For Marshal() expected %q got %q", expected, s)
+	}
+
+	// round trip ///////////////////////////////////////////////////////
+	var back config
+	if err := Unmarshal(content, &back); err != nil {
+		t.Fatal("For", "Unmarshal(Marshal())", "expected", nil, "got", err)
+	}
+	s := fmt.Sprintf("%s %s %v %v", back.Name, back.Server.Host, back.Server.Timeout, back.Upstream.Servers)
+	if expected := "demo 127.0.0.1 3s [a b]"; s != expected {
+		t.Error("For", "Unmarshal(Marshal())", "expected", expected, "got", s)
+	}
+}
@@ -0,0 +1,254 @@
+package ini
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotExist is returned by the typed getters when the item does not exists
+var ErrNotExist = errors.New("item does not exists")
+
+// byteUnits gives the multiplier of each GetByteSize unit, in lower case
+var byteUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"m":   1 << 20,
+	"g":   1 << 30,
+	"t":   1 << 40,
+	"p":   1 << 50,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"pb":  1e15,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+}
+
+// getValue returns the value of an item, or an error wrapping ErrNotExist
+func (ini *Ini) getValue(section string, item string) (string, error) {
+	value, exists := ini.GetItem(section, item)
+	if !exists {
+		return "", fmt.Errorf("%s.%s: %w", section, item, ErrNotExist)
+	}
+	return value, nil
+}
+
+// convertError explains why the value of an item cannot be converted
+func convertError(section string, item string, value string, kind string, err error) error {
+	return fmt.Errorf("%s.%s: cannot convert %q to %s: %w", section, item, value, kind, err)
+}
+
+/*
+GetInt returns the value of an item as an int
+
+Example :
+
+	port, err := myIni.GetInt("Server","port")
+*/
+func (ini *Ini) GetInt(section string, item string) (int, error) {
+	value, err := ini.getValue(section, item)
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, convertError(section, item, value, "int", err)
+	}
+	return i, nil
+}
+
+// GetIntDefault returns the value of an item as an int, or def if the item does not exists or is not an int
+func (ini *Ini) GetIntDefault(section string, item string, def int) int {
+	if i, err := ini.GetInt(section, item); err == nil {
+		return i
+	}
+	return def
+}
+
+// GetInt64 returns the value of an item as an int64
+func (ini *Ini) GetInt64(section string, item string) (int64, error) {
+	value, err := ini.getValue(section, item)
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, convertError(section, item, value, "int64", err)
+	}
+	return i, nil
+}
+
+// GetInt64Default returns the value of an item as an int64, or def if the item does not exists or is not an int64
+func (ini *Ini) GetInt64Default(section string, item string, def int64) int64 {
+	if i, err := ini.GetInt64(section, item); err == nil {
+		return i
+	}
+	return def
+}
+
+// GetUint returns the value of an item as an uint
+func (ini *Ini) GetUint(section string, item string) (uint, error) {
+	value, err := ini.getValue(section, item)
+	if err != nil {
+		return 0, err
+	}
+	i, err := strconv.ParseUint(value, 10, strconv.IntSize)
+	if err != nil {
+		return 0, convertError(section, item, value, "uint", err)
+	}
+	return uint(i), nil
+}
+
+// GetUintDefault returns the value of an item as an uint, or def if the item does not exists or is not an uint
+func (ini *Ini) GetUintDefault(section string, item string, def uint) uint {
+	if i, err := ini.GetUint(section, item); err == nil {
+		return i
+	}
+	return def
+}
+
+// GetFloat64 returns the value of an item as a float64
+func (ini *Ini) GetFloat64(section string, item string) (float64, error) {
+	value, err := ini.getValue(section, item)
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, convertError(section, item, value, "float64", err)
+	}
+	return f, nil
+}
+
+// GetFloat64Default returns the value of an item as a float64, or def if the item does not exists or is not a float64
+func (ini *Ini) GetFloat64Default(section string, item string, def float64) float64 {
+	if f, err := ini.GetFloat64(section, item); err == nil {
+		return f
+	}
+	return def
+}
+
+// parseBool converts yes/no, on/off, true/false and 1/0 (case insensitive) to a bool
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "1", "true", "yes", "on":
+		return true, nil
+	case "0", "false", "no", "off":
+		return false, nil
+	}
+	return false, errors.New("expected yes/no, on/off, true/false or 1/0")
+}
+
+/*
+GetBool returns the value of an item as a bool
+
+Accepted values are yes/no, on/off, true/false and 1/0 (case insensitive)
+*/
+func (ini *Ini) GetBool(section string, item string) (bool, error) {
+	value, err := ini.getValue(section, item)
+	if err != nil {
+		return false, err
+	}
+	b, err := parseBool(value)
+	if err != nil {
+		return false, convertError(section, item, value, "bool", err)
+	}
+	return b, nil
+}
+
+// GetBoolDefault returns the value of an item as a bool, or def if the item does not exists or is not a bool
+func (ini *Ini) GetBoolDefault(section string, item string, def bool) bool {
+	if b, err := ini.GetBool(section, item); err == nil {
+		return b
+	}
+	return def
+}
+
+/*
+GetDuration returns the value of an item as a time.Duration, see time.ParseDuration for the format
+
+Example :
+
+	timeout, err := myIni.GetDuration("Server","timeout") // timeout=1m30s
+*/
+func (ini *Ini) GetDuration(section string, item string) (time.Duration, error) {
+	value, err := ini.getValue(section, item)
+	if err != nil {
+		return 0, err
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, convertError(section, item, value, "duration", err)
+	}
+	return d, nil
+}
+
+// GetDurationDefault returns the value of an item as a time.Duration, or def if the item does not exists or is not a duration
+func (ini *Ini) GetDurationDefault(section string, item string, def time.Duration) time.Duration {
+	if d, err := ini.GetDuration(section, item); err == nil {
+		return d
+	}
+	return def
+}
+
+// parseByteSize converts a size like "512", "10MB" or "1.5GiB" into a number of bytes
+func parseByteSize(value string) (uint64, error) {
+	value = strings.TrimSpace(value)
+	end := len(value)
+	for end > 0 && !(value[end-1] >= '0' && value[end-1] <= '9' || value[end-1] == '.') {
+		end--
+	}
+	unit := strings.ToLower(strings.TrimSpace(value[end:]))
+	multiplier, exists := byteUnits[unit]
+	if !exists {
+		return 0, errors.New("unknown unit " + strconv.Quote(value[end:]))
+	}
+	f, err := strconv.ParseFloat(value[:end], 64)
+	if err != nil {
+		return 0, err
+	}
+	size := f * multiplier
+	if size < 0 || size >= math.MaxUint64 {
+		return 0, errors.New("size out of range")
+	}
+	return uint64(size), nil
+}
+
+/*
+GetByteSize returns the value of an item as a number of bytes
+
+The number can be followed by a unit : B, KB, MB, GB, TB, PB (powers of 1000),
+KiB, MiB, GiB, TiB, PiB or K, M, G, T, P (powers of 1024), case insensitive
+
+Example :
+
+	maxSize, err := myIni.GetByteSize("Upload","max_size") // max_size=10MB
+*/
+func (ini *Ini) GetByteSize(section string, item string) (uint64, error) {
+	value, err := ini.getValue(section, item)
+	if err != nil {
+		return 0, err
+	}
+	size, err := parseByteSize(value)
+	if err != nil {
+		return 0, convertError(section, item, value, "byte size", err)
+	}
+	return size, nil
+}
+
+// GetByteSizeDefault returns the value of an item as a number of bytes, or def if the item does not exists or is not a size
+func (ini *Ini) GetByteSizeDefault(section string, item string, def uint64) uint64 {
+	if size, err := ini.GetByteSize(section, item); err == nil {
+		return size
+	}
+	return def
+}
@@ -0,0 +1,74 @@
+package ini
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTyped(t *testing.T) {
+	myIni := new(Ini)
+	content := `
+[typed]
+int=-42
+uint=42
+float=3.5
+yes=Yes
+off=off
+duration=1m30s
+size=10MB
+kib=512KiB
+bad=abc
+`
+	myIni.LoadFromString(&content)
+
+	if i, err := myIni.GetInt("typed", "int"); i != -42 || err != nil {
+		t.Error("For", "GetInt(typed,int)", "expected", -42, "got", i, err)
+	}
+	if i, err := myIni.GetInt64("typed", "int"); i != -42 || err != nil {
+		t.Error("For", "GetInt64(typed,int)", "expected", -42, "got", i, err)
+	}
+	if i, err := myIni.GetUint("typed", "uint"); i != 42 || err != nil {
+		t.Error("For", "GetUint(typed,uint)", "expected", 42, "got", i, err)
+	}
+	if _, err := myIni.GetUint("typed", "int"); err == nil {
+		t.Error("For", "GetUint(typed,int)", "expected", "an error", "got", err)
+	}
+	if f, err := myIni.GetFloat64("typed", "float"); f != 3.5 || err != nil {
+		t.Error("For", "GetFloat64(typed,float)", "expected", 3.5, "got", f, err)
+	}
+	if b, err := myIni.GetBool("typed", "yes"); !b || err != nil {
+		t.Error("For", "GetBool(typed,yes)", "expected", true, "got", b, err)
+	}
+	if b, err := myIni.GetBool("typed", "off"); b || err != nil {
+		t.Error("For", "GetBool(typed,off)", "expected", false, "got", b, err)
+	}
+	if d, err := myIni.GetDuration("typed", "duration"); d != 90*time.Second || err != nil {
+		t.Error("For", "GetDuration(typed,duration)", "expected", 90*time.Second, "got", d, err)
+	}
+	if size, err := myIni.GetByteSize("typed", "size"); size != 10000000 || err != nil {
+		t.Error("For", "GetByteSize(typed,size)", "expected", 10000000, "got", size, err)
+	}
+	if size, err := myIni.GetByteSize("typed", "kib"); size != 512*1024 || err != nil {
+		t.Error("For", "GetByteSize(typed,kib)", "expected", 512*1024, "got", size, err)
+	}
+
+	// errors ///////////////////////////////////////////////////////
+	if _, err := myIni.GetInt("typed", "does not exists"); !errors.Is(err, ErrNotExist) {
+		t.Error("For", "GetInt(typed,does not exists)", "expected", ErrNotExist, "got", err)
+	}
+	if _, err := myIni.GetBool("typed", "bad"); err == nil {
+		t.Error("For", "GetBool(typed,bad)", "expected", "an error", "got", err)
+	}
+
+	// defaults ///////////////////////////////////////////////////////
+	if i := myIni.GetIntDefault("typed", "bad", 80); i != 80 {
+		t.Error("For", "GetIntDefault(typed,bad,80)", "expected", 80, "got", i)
+	}
+	if i := myIni.GetIntDefault("typed", "int", 80); i != -42 {
+		t.Error("For", "GetIntDefault(typed,int,80)", "expected", -42, "got", i)
+	}
+	if d := myIni.GetDurationDefault("typed", "does not exists", time.Second); d != time.Second {
+		t.Error("For", "GetDurationDefault(typed,does not exists)", "expected", time.Second, "got", d)
+	}
+}
@@ -0,0 +1,124 @@
+package ini
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// fileState identifies a version of a file
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// fileStates returns the state of each file, a missing file has a zero state
+func fileStates(files []string) []fileState {
+	states := make([]fileState, len(files))
+	for i, name := range files {
+		if info, err := os.Stat(name); err == nil {
+			states[i] = fileState{info.ModTime(), info.Size()}
+		}
+	}
+	return states
+}
+
+/*
+Watch checks the loaded file (and the files it includes) every WatchInterval, and reloads it when modified,
+once the files are unchanged during an interval so that a file being written is not read
+
+The new content replaces the current one only if it is loaded without error, then fn is called with a copy
+of the previous content, the updated object and the list of the sections and items added, removed or changed.
+fn is not called if nothing changed. Watch returns when ctx is done.
+Watch does not synchronize with the other methods running in other goroutines
+
+Example :
+
+	go myIni.Watch(ctx, func(old, new *ini.Ini, changes []ini.Change) {
+		for _, change := range changes {
+			print(change.Kind.String(), " ", change.Section, " ", change.Item, "\n")
+		}
+	})
+*/
+func (ini *Ini) Watch(ctx context.Context, fn func(old, new *Ini, changes []Change)) error {
+	return ini.watch(ctx, func(next *Ini) {
+		old := *ini
+		ini.replaceData(next)
+		if changes := changes(&old, ini); len(changes) > 0 {
+			fn(&old, ini, changes)
+		}
+	})
+}
+
+// watch calls update with the reloaded content each time the loaded files are modified
+func (ini *Ini) watch(ctx context.Context, update func(next *Ini)) error {
+	if ini.Filename == "" {
+		return errors.New("You must load a file before watching it")
+	}
+	interval := ini.WatchInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	files := ini.loaded
+	if len(files) == 0 {
+		files = []string{ini.Filename}
+	}
+	states := fileStates(files)
+	pending := states // modified states, reloaded once unchanged during an interval
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		current := fileStates(files)
+		if sameStates(states, current) || !sameStates(pending, current) {
+			pending = current // unchanged, or still being written
+			continue
+		}
+
+		next := Ini{CollectErrors: ini.CollectErrors} // the only setting read by the parser, update takes the data
+		err := next.LoadFromFileWithOptions(ini.Filename, ini.options)
+		if !sameStates(current, fileStates(files)) {
+			continue // modified while reading, read again once stable
+		}
+		states = current
+		if err != nil {
+			continue // keep the current content, retry on the next modification
+		}
+		files = next.loaded // the included files may have changed
+		states = fileStates(files)
+		pending = states
+		update(&next)
+	}
+}
+
+// sameStates returns true if no file changed
+func sameStates(a []fileState, b []fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].modTime.Equal(b[i].modTime) || a[i].size != b[i].size {
+			return false
+		}
+	}
+	return true
+}
+
+// replaceData replaces the content of ini by the content of next, keeping the settings
+func (ini *Ini) replaceData(next *Ini) {
+	ini.data = next.data
+	ini.order = next.order
+	ini.trailing = next.trailing
+	ini.warnings = next.warnings
+	ini.loaded = next.loaded
+	ini.source = next.source
+	ini.detected = next.detected
+}
@@ -0,0 +1,111 @@
+package ini
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// replaceFile writes content to filename at once, the watcher must not read a truncated file
+func replaceFile(filename string, content []byte) {
+	ioutil.WriteFile(filename+".tmp", content, 0644)
+	os.Rename(filename+".tmp", filename)
+}
+
+func TestWatch(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.ini")
+	ioutil.WriteFile(filename, []byte("[a]\nx=1\ny=2\n[b]\nz=3\n"), 0644)
+
+	myIni := new(Ini)
+	myIni.LoadFromFile(filename)
+	myIni.WatchInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	type update struct {
+		old, new string
+		changes  []Change
+	}
+	updates := make(chan update, 10)
+	done := make(chan error)
+	go func() {
+		done <- myIni.Watch(ctx, func(old, new *Ini, changes []Change) {
+			oldX, _ := old.Get("a", "x")
+			newX, _ := new.Get("a", "x")
+			updates <- update{oldX, newX, changes}
+		})
+	}()
+
+	// an invalid file is ignored ///////////////////////////////////////////////////////
+	time.Sleep(30 * time.Millisecond)
+	replaceFile(filename, []byte("[a\n"))
+	os.Chtimes(filename, time.Now(), time.Now().Add(time.Second))
+	time.Sleep(50 * time.Millisecond)
+	if s, _ := myIni.Get("a", "y"); s != "2" {
+		t.Error("For", "Watch(invalid file)", "expected", "2", "got", s)
+	}
+
+	// a valid file is swapped in ///////////////////////////////////////////////////////
+	replaceFile(filename, []byte("[a]\nx=10\nw=4\n[c]\nv=5\n"))
+	os.Chtimes(filename, time.Now(), time.Now().Add(2*time.Second))
+
+	select {
+	case u := <-updates:
+		if u.old != "1" || u.new != "10" {
+			t.Error("For", "Watch()", "expected", "1 -> 10", "got", u.old, "->", u.new)
+		}
+		s := ""
+		for _, c := range u.changes {
+			s += fmt.Sprintf("%s %s %s %v %v; ", c.Kind, c.Section, c.Item, c.OldValues, c.NewValues)
+		}
+		expected := "item changed a x [1] [10]; item removed a y [2] []; item added a w [] [4]; " +
+			"section removed b  [] []; item removed b z [3] []; section added c  [] []; item added c v [] [5]; "
+		if s != expected {
+			t.Error("For", "Watch() changes", "expected", expected, "got", s)
+		}
+	case <-ctx.Done():
+		t.Fatal("For", "Watch()", "expected", "an update", "got", "none")
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Error("For", "Watch() end", "expected", context.Canceled, "got", err)
+	}
+}
+
+func TestWatchPartialWrite(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.ini")
+	ioutil.WriteFile(filename, []byte("[a]\nx=1\ny=2\n"), 0644)
+
+	myIni := new(Ini)
+	myIni.LoadFromFile(filename)
+	myIni.WatchInterval = 100 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	updates := make(chan []Change, 10)
+	go myIni.Watch(ctx, func(old, new *Ini, changes []Change) {
+		updates <- changes
+	})
+
+	// a file written in two steps is read once complete ///////////////////////////////////////////////////////
+	time.Sleep(150 * time.Millisecond)
+	ioutil.WriteFile(filename, []byte("[a]\n"), 0644) // truncated, but valid
+	time.Sleep(10 * time.Millisecond)
+	ioutil.WriteFile(filename, []byte("[a]\nx=10\ny=2\n"), 0644)
+
+	select {
+	case changes := <-updates:
+		if len(changes) != 1 || changes[0].Kind != ItemChanged || changes[0].Item != "x" {
+			t.Error("For", "Watch(partial write)", "expected", "x changed", "got", changes)
+		}
+	case <-ctx.Done():
+		t.Fatal("For", "Watch(partial write)", "expected", "an update", "got", "none")
+	}
+}